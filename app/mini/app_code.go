@@ -81,7 +81,7 @@ func (a *AppCode) GetUnlimit(scene string, option ...util.Map) core.Responder {
 	return responder
 }
 
-func (a *AppCode) getStream(url string, m util.Map) core.Responder {
+func (a *AppCode) getStream(url string, m interface{}) core.Responder {
 	log.Debug("AppCode|getStream", url, m)
 	token := a.AccessToken().GetToken()
 	responder := core.PostJSON(url, token.KeyMap(), m)
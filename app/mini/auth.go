@@ -1,6 +1,8 @@
 package mini
 
 import (
+	"strings"
+
 	"github.com/godcong/wego/cipher"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/log"
@@ -68,19 +70,47 @@ func (a *Auth) Session(code string) core.Responder {
 	return resp
 }
 
+/*SessionSignature 用户登录态签名,以session_key为密钥对空字符串进行HMAC-SHA256签名 */
+func SessionSignature(sessionKey string) string {
+	return strings.ToLower(util.MakeSignHMACSHA256("", sessionKey))
+}
+
+/*CheckSessionKey 检验登录态
+校验服务器所保存的登录态session_key是否合法
+接口地址:
+GET https://api.weixin.qq.com/wxa/checksession?access_token=ACCESS_TOKEN&signature=SIGNATURE&openid=OPENID&sig_method=SIG_METHOD
+成功:
+{"errcode":0,"errmsg":"ok"}
+失败:
+{"errcode":87009,"errmsg":"invalid signature"}
+*/
+func (a *Auth) CheckSessionKey(openID, sessionKey string) core.Responder {
+	return core.Get(Link(wxaChecksession), a.sessionParams(openID, sessionKey))
+}
+
 // CheckSession ...
+//Deprecated: 缺少openid及签名,不再发起请求,请使用CheckSessionKey
 func (a *Auth) CheckSession(session string) core.Responder {
-	//TODO
-	params := util.Map{
-		"appid":      a.Get("app_id"),
-		"secret":     a.Get("secret"),
-		"sig_method": "hmac_sha256",
-	}
-	resp := core.Get(
-		Link(wxaChecksession),
-		params,
-	)
-	return resp
+	return core.Err(nil, ErrDeprecated)
+}
+
+/*ResetUserSessionKey 重置登录态
+重置指定的登录态session_key,返回新的session_key
+接口地址:
+GET https://api.weixin.qq.com/wxa/resetusersessionkey?access_token=ACCESS_TOKEN&signature=SIGNATURE&openid=OPENID&sig_method=SIG_METHOD
+成功:
+{"openid":"OPENID","session_key":"SESSION_KEY","errcode":0,"errmsg":"ok"}
+*/
+func (a *Auth) ResetUserSessionKey(openID, sessionKey string) core.Responder {
+	return core.Get(Link(wxaResetUserSessionKey), a.sessionParams(openID, sessionKey))
+}
+
+func (a *Auth) sessionParams(openID, sessionKey string) util.Map {
+	p := a.accessToken.GetToken().KeyMap()
+	p.Set("openid", openID)
+	p.Set("signature", SessionSignature(sessionKey))
+	p.Set("sig_method", "hmac_sha256")
+	return p
 }
 
 //UserInfoByCode 从Code获取用户信息
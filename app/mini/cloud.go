@@ -0,0 +1,318 @@
+package mini
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+// MaxCloudFiles 批量操作云存储文件的数量上限
+const MaxCloudFiles = 50
+
+/*CloudPager 分页信息 */
+type CloudPager struct {
+	Offset int   `json:"Offset"`
+	Limit  int   `json:"Limit"`
+	Total  int64 `json:"Total"`
+}
+
+/*CloudQueryResult 数据库查询结果,data中每条记录为json字符串 */
+type CloudQueryResult struct {
+	Pager CloudPager `json:"pager"`
+	Data  []string   `json:"data"`
+}
+
+/*Decode 将查询到的记录解析到v中,v须为切片指针 */
+func (r *CloudQueryResult) Decode(v interface{}) error {
+	return decodeCloudData(r.Data, v)
+}
+
+/*CloudUpdateResult 数据库更新结果 */
+type CloudUpdateResult struct {
+	Matched  int64  `json:"matched"`
+	Modified int64  `json:"modified"`
+	ID       string `json:"id"` //upsert时新增的记录id
+}
+
+/*CloudUploadMeta 上传文件链接信息 */
+type CloudUploadMeta struct {
+	URL           string `json:"url"`
+	Token         string `json:"token"`
+	Authorization string `json:"authorization"`
+	FileID        string `json:"file_id"`
+	CosFileID     string `json:"cos_file_id"`
+}
+
+/*CloudFile 获取文件下载链接参数 */
+type CloudFile struct {
+	FileID string `json:"fileid"`
+	MaxAge int64  `json:"max_age"` //下载链接有效期,单位秒
+}
+
+/*CloudDownloadFile 文件下载链接 */
+type CloudDownloadFile struct {
+	FileID      string `json:"fileid"`
+	DownloadURL string `json:"download_url"`
+	Status      int    `json:"status"`
+	ErrMsg      string `json:"errmsg"`
+}
+
+/*CloudDeleteFile 文件删除结果 */
+type CloudDeleteFile struct {
+	FileID string `json:"fileid"`
+	Status int    `json:"status"`
+	ErrMsg string `json:"errmsg"`
+}
+
+/*CloudCollection 集合信息 */
+type CloudCollection struct {
+	Name       string `json:"name"`
+	Count      int64  `json:"count"`
+	Size       int64  `json:"size"`
+	IndexCount int    `json:"index_count"`
+	IndexSize  int64  `json:"index_size"`
+}
+
+/*CloudCollectionList 集合列表 */
+type CloudCollectionList struct {
+	Collections []*CloudCollection `json:"collections"`
+	Pager       CloudPager         `json:"pager"`
+}
+
+/*Cloud 云开发
+使用小程序access_token操作云开发环境,环境ID默认读取配置中的cloud_env,可通过Env切换 */
+type Cloud struct {
+	*Program
+	env string
+}
+
+func newCloud(program *Program) interface{} {
+	return &Cloud{
+		Program: program,
+		env:     program.GetString("cloud_env"),
+	}
+}
+
+/*NewCloud NewCloud */
+func NewCloud(config *core.Config) *Cloud {
+	return newCloud(NewMiniProgram(config)).(*Cloud)
+}
+
+/*Env 返回使用指定云环境的Cloud */
+func (c *Cloud) Env(env string) *Cloud {
+	return &Cloud{
+		Program: c.Program,
+		env:     env,
+	}
+}
+
+/*InvokeCloudFunction 触发云函数,data为云函数的传入参数,可为json字符串或可json序列化的结构,返回云函数的返回值
+接口地址:
+POST https://api.weixin.qq.com/tcb/invokecloudfunction?access_token=ACCESS_TOKEN&env=ENV&name=FUNCTION_NAME
+成功:
+{"errcode":0,"errmsg":"ok","resp_data":"{\"event\":{\"userInfo\":{\"appId\":\"SAMPLEAPPID\"}},\"appid\":\"SAMPLEAPPID\"}"}
+*/
+func (c *Cloud) InvokeCloudFunction(name string, data interface{}) ([]byte, error) {
+	log.Debug("Cloud|InvokeCloudFunction", name, data)
+	if c.env == "" {
+		return nil, ErrCloudEnv
+	}
+	if data == nil {
+		data = "{}"
+	}
+	key := c.accessToken.GetToken().KeyMap()
+	key.Set("env", c.env)
+	key.Set("name", name)
+	var v struct {
+		RespData string `json:"resp_data"`
+	}
+	if err := unmarshalResult(core.PostJSON(Link(tcbInvokeCloudFunction), key, data), &v); err != nil {
+		return nil, err
+	}
+	return []byte(v.RespData), nil
+}
+
+/*DatabaseAdd 数据库插入记录,返回插入成功的记录id
+接口地址:
+POST https://api.weixin.qq.com/tcb/databaseadd?access_token=ACCESS_TOKEN
+参数	必填	说明
+query	是	数据库操作语句,如db.collection("geo").add({data:[{description:"item1"}]})
+*/
+func (c *Cloud) DatabaseAdd(query string) ([]string, error) {
+	var v struct {
+		IDList []string `json:"id_list"`
+	}
+	return v.IDList, c.post(tcbDatabaseAdd, util.Map{"query": query}, &v)
+}
+
+/*DatabaseDelete 数据库删除记录,返回删除记录数量
+接口地址:
+POST https://api.weixin.qq.com/tcb/databasedelete?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) DatabaseDelete(query string) (int64, error) {
+	var v struct {
+		Deleted int64 `json:"deleted"`
+	}
+	return v.Deleted, c.post(tcbDatabaseDelete, util.Map{"query": query}, &v)
+}
+
+/*DatabaseUpdate 数据库更新记录
+接口地址:
+POST https://api.weixin.qq.com/tcb/databaseupdate?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) DatabaseUpdate(query string) (*CloudUpdateResult, error) {
+	var v CloudUpdateResult
+	return &v, c.post(tcbDatabaseUpdate, util.Map{"query": query}, &v)
+}
+
+/*DatabaseQuery 数据库查询记录
+接口地址:
+POST https://api.weixin.qq.com/tcb/databasequery?access_token=ACCESS_TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","pager":{"Offset":0,"Limit":10,"Total":1},"data":["{\"_id\":\"be62d9c4-43ec-4dc6-8ca1-30b206eeed19\"}"]}
+*/
+func (c *Cloud) DatabaseQuery(query string) (*CloudQueryResult, error) {
+	var v CloudQueryResult
+	return &v, c.post(tcbDatabaseQuery, util.Map{"query": query}, &v)
+}
+
+/*DatabaseAggregate 数据库聚合记录,返回的每条记录为json字符串
+接口地址:
+POST https://api.weixin.qq.com/tcb/databaseaggregate?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) DatabaseAggregate(query string) ([]string, error) {
+	var v struct {
+		Data []string `json:"data"`
+	}
+	return v.Data, c.post(tcbDatabaseAggregate, util.Map{"query": query}, &v)
+}
+
+/*DatabaseCount 统计集合记录数或统计查询语句对应的结果记录数
+接口地址:
+POST https://api.weixin.qq.com/tcb/databasecount?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) DatabaseCount(query string) (int64, error) {
+	var v struct {
+		Count int64 `json:"count"`
+	}
+	return v.Count, c.post(tcbDatabaseCount, util.Map{"query": query}, &v)
+}
+
+/*DatabaseCollectionAdd 新增集合
+接口地址:
+POST https://api.weixin.qq.com/tcb/databasecollectionadd?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) DatabaseCollectionAdd(name string) error {
+	return c.post(tcbDatabaseCollectionAdd, util.Map{"collection_name": name}, nil)
+}
+
+/*DatabaseCollectionDelete 删除集合
+接口地址:
+POST https://api.weixin.qq.com/tcb/databasecollectiondelete?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) DatabaseCollectionDelete(name string) error {
+	return c.post(tcbDatabaseCollectionDelete, util.Map{"collection_name": name}, nil)
+}
+
+/*DatabaseCollectionGet 获取特定云环境下集合信息
+接口地址:
+POST https://api.weixin.qq.com/tcb/databasecollectionget?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) DatabaseCollectionGet(limit, offset int) (*CloudCollectionList, error) {
+	var v CloudCollectionList
+	return &v, c.post(tcbDatabaseCollectionGet, util.Map{
+		"limit":  limit,
+		"offset": offset,
+	}, &v)
+}
+
+/*GetUploadMeta 获取文件上传链接
+接口地址:
+POST https://api.weixin.qq.com/tcb/uploadfile?access_token=ACCESS_TOKEN
+参数	必填	说明
+path	是	上传路径
+*/
+func (c *Cloud) GetUploadMeta(path string) (*CloudUploadMeta, error) {
+	var v CloudUploadMeta
+	if err := c.post(tcbUploadFile, util.Map{"path": path}, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+/*UploadFile 上传本地文件至云存储的path路径,返回文件ID */
+func (c *Cloud) UploadFile(path, filePath string) (string, error) {
+	log.Debug("Cloud|UploadFile", path, filePath)
+	meta, err := c.GetUploadMeta(path)
+	if err != nil {
+		return "", err
+	}
+	resp := core.Upload(meta.URL, util.Map{}, util.Map{
+		"name":  "file",
+		"media": filePath,
+		"fields": util.Map{
+			"key":                   path,
+			"Signature":             meta.Authorization,
+			"x-cos-security-token":  meta.Token,
+			"x-cos-meta-fileid":     meta.CosFileID,
+			"success_action_status": "200",
+		},
+	})
+	if err := resp.Error(); err != nil {
+		return "", err
+	}
+	return meta.FileID, nil
+}
+
+/*BatchDownloadFile 获取文件下载链接,一次最多50个
+接口地址:
+POST https://api.weixin.qq.com/tcb/batchdownloadfile?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) BatchDownloadFile(files ...*CloudFile) ([]*CloudDownloadFile, error) {
+	if len(files) == 0 || len(files) > MaxCloudFiles {
+		return nil, ErrCloudFileList
+	}
+	var v struct {
+		FileList []*CloudDownloadFile `json:"file_list"`
+	}
+	return v.FileList, c.post(tcbBatchDownloadFile, util.Map{"file_list": files}, &v)
+}
+
+/*BatchDeleteFile 删除云存储文件,一次最多50个
+接口地址:
+POST https://api.weixin.qq.com/tcb/batchdeletefile?access_token=ACCESS_TOKEN
+*/
+func (c *Cloud) BatchDeleteFile(fileIDs ...string) ([]*CloudDeleteFile, error) {
+	if len(fileIDs) == 0 || len(fileIDs) > MaxCloudFiles {
+		return nil, ErrCloudFileList
+	}
+	var v struct {
+		DeleteList []*CloudDeleteFile `json:"delete_list"`
+	}
+	return v.DeleteList, c.post(tcbBatchDeleteFile, util.Map{"fileid_list": fileIDs}, &v)
+}
+
+func (c *Cloud) post(url string, body util.Map, v interface{}) error {
+	log.Debug("Cloud|post", url, body)
+	if c.env == "" {
+		return ErrCloudEnv
+	}
+	body.Set("env", c.env)
+	key := c.accessToken.GetToken().KeyMap()
+	return unmarshalResult(core.PostJSON(Link(url), key, body), v)
+}
+
+func decodeCloudData(data []string, v interface{}) error {
+	buf := bytes.NewBufferString("[")
+	for i, d := range data {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(d)
+	}
+	buf.WriteByte(']')
+	return json.Unmarshal(buf.Bytes(), v)
+}
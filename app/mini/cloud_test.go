@@ -0,0 +1,41 @@
+package mini_test
+
+import (
+	"testing"
+
+	"github.com/godcong/wego/app/mini"
+)
+
+// TestCloud_Env ...
+func TestCloud_Env(t *testing.T) {
+	cloud := mini.NewCloud(cfg)
+	if _, err := cloud.DatabaseCount(`db.collection("geo").count()`); err != mini.ErrCloudEnv {
+		t.Error(err)
+	}
+	if _, err := cloud.Env("").InvokeCloudFunction("login", nil); err != mini.ErrCloudEnv {
+		t.Error(err)
+	}
+	if _, err := cloud.Env("test-env").BatchDeleteFile(); err != mini.ErrCloudFileList {
+		t.Error(err)
+	}
+}
+
+// TestCloudQueryResult_Decode ...
+func TestCloudQueryResult_Decode(t *testing.T) {
+	r := &mini.CloudQueryResult{
+		Data: []string{
+			`{"_id":"be62d9c4-43ec-4dc6-8ca1-30b206eeed19","description":"item1"}`,
+			`{"_id":"c3b8e1f2-7a1d-4c2e-9d5f-0e6a4b2c8d71","description":"item2"}`,
+		},
+	}
+	var items []struct {
+		ID          string `json:"_id"`
+		Description string `json:"description"`
+	}
+	if err := r.Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[1].Description != "item2" {
+		t.Error(items)
+	}
+}
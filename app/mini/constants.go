@@ -1,7 +1,123 @@
 package mini
 
+import "errors"
+
 const domain = "https://api.weixin.qq.com"
 
+// ErrCustomerToUser 缺少touser
+var ErrCustomerToUser = errors.New("customer message touser is empty")
+
+// ErrCustomerMsgType 不支持的客服消息类型
+var ErrCustomerMsgType = errors.New("customer message type is not supported")
+
+// ErrCustomerContent 文本内容为空
+var ErrCustomerContent = errors.New("customer message content is empty")
+
+// ErrCustomerMediaID 缺少media_id
+var ErrCustomerMediaID = errors.New("customer message media id is empty")
+
+// ErrCustomerLink 图文链接缺少必填字段
+var ErrCustomerLink = errors.New("customer message link requires title, description, url and thumb_url")
+
+// ErrCustomerMiniProgramPage 小程序卡片缺少必填字段
+var ErrCustomerMiniProgramPage = errors.New("customer message miniprogrampage requires title, pagepath and thumb_media_id")
+
+// ErrSubscribeMessageRequired 缺少touser,template_id或data
+var ErrSubscribeMessageRequired = errors.New("subscribe message requires touser, template_id and data")
+
+// ErrMiniProgramState miniprogram_state错误
+var ErrMiniProgramState = errors.New("miniprogram_state must be developer, trial or formal")
+
+// ErrSubscribeKidList 关键词数量错误
+var ErrSubscribeKidList = errors.New("kidList must contain 2 to 5 keywords")
+
+// ErrSessionNotFound 登录态不存在或已过期
+var ErrSessionNotFound = errors.New("session not found or expired")
+
+// ErrSessionKeyEmpty 缺少openid或session_key
+var ErrSessionKeyEmpty = errors.New("session requires openid and session_key")
+
+// ErrSessionKeyInvalid 服务端保存的session_key已失效
+var ErrSessionKeyInvalid = errors.New("session key is invalid")
+
+// ErrDeprecated 接口已废弃
+var ErrDeprecated = errors.New("api is deprecated")
+
+// ErrDecryptData 解密encryptedData失败
+var ErrDecryptData = errors.New("decrypt encrypted data failed")
+
+// ErrWatermarkAppID watermark中appid与当前小程序不一致
+var ErrWatermarkAppID = errors.New("watermark appid mismatch")
+
+// ErrWatermarkExpired watermark时间戳已过期
+var ErrWatermarkExpired = errors.New("watermark timestamp expired")
+
+// ErrExpire 失效时间或失效间隔天数错误
+var ErrExpire = errors.New("expire_time must be set or expire_interval must be between 1 and 30")
+
+// ErrEnvVersion env_version错误
+var ErrEnvVersion = errors.New("env_version must be release, trial or develop")
+
+// ErrShortLinkPageURL 缺少page_url
+var ErrShortLinkPageURL = errors.New("short link requires page_url")
+
+// ErrPhoneInfoEmpty 返回中缺少phone_info
+var ErrPhoneInfoEmpty = errors.New("phone_info is empty")
+
+// ErrDateRange 开始日期晚于结束日期
+var ErrDateRange = errors.New("begin_date must not be after end_date")
+
+// ErrDateAfterYesterday 结束日期最大为昨日
+var ErrDateAfterYesterday = errors.New("end_date must not be later than yesterday")
+
+// ErrDateWeekly 周数据须从周一开始到周日结束
+var ErrDateWeekly = errors.New("weekly data must begin on monday and end on sunday")
+
+// ErrDateMonthly 月数据须从月初开始到月末结束
+var ErrDateMonthly = errors.New("monthly data must begin on the first day and end on the last day of a month")
+
+// ErrPortraitDateRange 用户画像只能查询最近1/7/30天
+var ErrPortraitDateRange = errors.New("user portrait date range must be 1, 7 or 30 days")
+
+// ErrPerformanceDateRange 性能数据时间跨度不能超过30天
+var ErrPerformanceDateRange = errors.New("performance data time range must be within 30 days")
+
+// ErrRealtimeLogDate 实时日志只能查询最近7天内同一天的数据
+var ErrRealtimeLogDate = errors.New("realtime log must be searched within one day of the last 7 days")
+
+// ErrExpressOrderRequired 缺少order_id,openid,delivery_id或biz_id
+var ErrExpressOrderRequired = errors.New("express order requires order_id, openid, delivery_id and biz_id")
+
+// ErrExpressContact 收寄件人信息不完整
+var ErrExpressContact = errors.New("express contact requires name, tel or mobile, province, city, area and address")
+
+// ErrExpressCargo 包裹信息不完整
+var ErrExpressCargo = errors.New("express cargo requires count, weight and detail_list")
+
+// ErrExpressService 缺少服务类型
+var ErrExpressService = errors.New("express service requires service_type and service_name")
+
+// ErrPrinterUpdateType 打印员更新类型错误
+var ErrPrinterUpdateType = errors.New("printer update_type must be bind or unbind")
+
+// ErrLiveRoomRequired 直播间缺少必填字段
+var ErrLiveRoomRequired = errors.New("live room requires name, anchor, coverImg, shareImg and feedsImg")
+
+// ErrLiveRoomTime 直播时间不符合要求
+var ErrLiveRoomTime = errors.New("live room must start 10 minutes to 6 months later and last 30 minutes to 24 hours")
+
+// ErrLiveGoodsRequired 商品缺少必填字段
+var ErrLiveGoodsRequired = errors.New("live goods requires coverImgUrl, name, url and price")
+
+// ErrLiveGoodsPrice 商品价格类型错误
+var ErrLiveGoodsPrice = errors.New("live goods priceType is invalid or price2 is missing")
+
+// ErrCloudEnv 缺少云开发环境ID
+var ErrCloudEnv = errors.New("cloud env is empty")
+
+// ErrCloudFileList 文件数量错误
+var ErrCloudFileList = errors.New("cloud file list must contain 1 to 50 files")
+
 const datacubeGetweanalysisappidvisitdistribution = "/datacube/getweanalysisappidvisitdistribution"
 const datacubeGetweanalysisappidvisitpage = "/datacube/getweanalysisappidvisitpage"
 const datacubeGetweanalysisappiduserportrait = "/datacube/getweanalysisappiduserportrait"
@@ -12,8 +128,13 @@ const datacubeGetweanalysisappidmonthlyvisittrend = "/datacube/getweanalysisappi
 const datacubeGetweanalysisappidweeklyvisittrend = "/datacube/getweanalysisappidweeklyvisittrend"
 const datacubeGetweanalysisappiddailyvisittrend = "/datacube/getweanalysisappiddailyvisittrend"
 const datacubeGetweanalysisappiddailysummarytrend = "/datacube/getweanalysisappiddailysummarytrend"
+const wxaBusinessPerformanceBoot = "/wxa/business/performance/boot"
+const wxaapiUserLogSearch = "/wxaapi/userlog/userlog_search"
 
 const customSend = "/cgi-bin/message/custom/send"
+const customTyping = "/cgi-bin/message/custom/typing"
+const mediaUpload = "/cgi-bin/media/upload"
+const mediaGet = "/cgi-bin/media/get"
 const templateSend = "/cgi-bin/message/wxopen/template/send"
 const templateAdd = "/cgi-bin/wxopen/template/add"
 const templateDel = "/cgi-bin/wxopen/template/del"
@@ -22,13 +143,72 @@ const templateList = "/cgi-bin/wxopen/template/list"
 const templateLibraryList = "/cgi-bin/wxopen/template/library/list"
 const templateLibraryGet = "/cgi-bin/wxopen/template/library/get"
 
+const wxaMsgSecCheck = "/wxa/msg_sec_check"
+const wxaImgSecCheck = "/wxa/img_sec_check"
+const wxaMediaCheckAsync = "/wxa/media_check_async"
+
+const expressDeliveryGetAll = "/cgi-bin/express/business/delivery/getall"
+const expressOrderAdd = "/cgi-bin/express/business/order/add"
+const expressOrderCancel = "/cgi-bin/express/business/order/cancel"
+const expressOrderGet = "/cgi-bin/express/business/order/get"
+const expressPathGet = "/cgi-bin/express/business/path/get"
+const expressPrinterGetAll = "/cgi-bin/express/business/printer/getall"
+const expressPrinterUpdate = "/cgi-bin/express/business/printer/update"
+const expressQuotaGet = "/cgi-bin/express/business/quota/get"
+
+const wxaBusinessGetLiveInfo = "/wxa/business/getliveinfo"
+const wxaapiBroadcastRoomCreate = "/wxaapi/broadcast/room/create"
+const wxaapiBroadcastRoomAddGoods = "/wxaapi/broadcast/room/addgoods"
+const wxaapiBroadcastRoomAddAssistant = "/wxaapi/broadcast/room/addassistant"
+const wxaapiBroadcastRoomModifyAssistant = "/wxaapi/broadcast/room/modifyassistant"
+const wxaapiBroadcastRoomRemoveAssistant = "/wxaapi/broadcast/room/removeassistant"
+const wxaapiBroadcastRoomGetAssistantList = "/wxaapi/broadcast/room/getassistantlist"
+const wxaapiBroadcastGoodsAdd = "/wxaapi/broadcast/goods/add"
+const wxaapiBroadcastGoodsAudit = "/wxaapi/broadcast/goods/audit"
+const wxaapiBroadcastGoodsResetAudit = "/wxaapi/broadcast/goods/resetaudit"
+const wxaapiBroadcastGoodsDelete = "/wxaapi/broadcast/goods/delete"
+const wxaapiBroadcastGoodsUpdate = "/wxaapi/broadcast/goods/update"
+const wxaapiBroadcastGoodsGetApproved = "/wxaapi/broadcast/goods/getapproved"
+const wxaapiBroadcastRoleAdd = "/wxaapi/broadcast/role/addrole"
+const wxaapiBroadcastRoleDelete = "/wxaapi/broadcast/role/deleterole"
+const wxaapiBroadcastRoleGetList = "/wxaapi/broadcast/role/getrolelist"
+
+const tcbInvokeCloudFunction = "/tcb/invokecloudfunction"
+const tcbDatabaseAdd = "/tcb/databaseadd"
+const tcbDatabaseDelete = "/tcb/databasedelete"
+const tcbDatabaseUpdate = "/tcb/databaseupdate"
+const tcbDatabaseQuery = "/tcb/databasequery"
+const tcbDatabaseAggregate = "/tcb/databaseaggregate"
+const tcbDatabaseCount = "/tcb/databasecount"
+const tcbDatabaseCollectionAdd = "/tcb/databasecollectionadd"
+const tcbDatabaseCollectionDelete = "/tcb/databasecollectiondelete"
+const tcbDatabaseCollectionGet = "/tcb/databasecollectionget"
+const tcbUploadFile = "/tcb/uploadfile"
+const tcbBatchDownloadFile = "/tcb/batchdownloadfile"
+const tcbBatchDeleteFile = "/tcb/batchdeletefile"
+
+const subscribeSend = "/cgi-bin/message/subscribe/send"
+const newtmplGetCategory = "/wxaapi/newtmpl/getcategory"
+const newtmplGetPubTemplateTitles = "/wxaapi/newtmpl/getpubtemplatetitles"
+const newtmplGetPubTemplateKeywords = "/wxaapi/newtmpl/getpubtemplatekeywords"
+const newtmplAddTemplate = "/wxaapi/newtmpl/addtemplate"
+const newtmplDelTemplate = "/wxaapi/newtmpl/deltemplate"
+const newtmplGetTemplate = "/wxaapi/newtmpl/gettemplate"
+
 const snsJscode2session = "/sns/jscode2session"
 
 const wxaRemoveUserStorage = "wxa/remove_user_storage"
 const wxaSetUserStorage = "wxa/set_user_storage"
-const wxaChecksession = "wxa/checksession"
+const wxaChecksession = "/wxa/checksession"
+const wxaResetUserSessionKey = "/wxa/resetusersessionkey"
 const wxaappCreatewxaqrcode = "/cgi-bin/wxaapp/createwxaqrcode"
 const wxaGetWXACodeUnlimit = "/wxa/getwxacodeunlimit"
 const wxaPlugin = "wxa/plugin"
 const wxaGetWXACode = "/wxa/getwxacode"
 const wxaDevPlugin = "/wxa/devplugin"
+const wxaBusinessGetUserPhoneNumber = "/wxa/business/getuserphonenumber"
+const wxaGenerateScheme = "/wxa/generatescheme"
+const wxaQueryScheme = "/wxa/queryscheme"
+const wxaGenerateURLLink = "/wxa/generate_urllink"
+const wxaQueryURLLink = "/wxa/query_urllink"
+const wxaGenerateShortLink = "/wxa/genwxashortlink"
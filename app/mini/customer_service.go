@@ -0,0 +1,197 @@
+package mini
+
+import (
+	"encoding/json"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+/*CustomerText 文本消息内容 */
+type CustomerText struct {
+	Content string `json:"content"`
+}
+
+/*CustomerImage 图片消息内容 */
+type CustomerImage struct {
+	MediaID string `json:"media_id"`
+}
+
+/*CustomerLink 图文链接消息内容 */
+type CustomerLink struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	ThumbURL    string `json:"thumb_url"`
+}
+
+/*CustomerMiniProgramPage 小程序卡片消息内容 */
+type CustomerMiniProgramPage struct {
+	Title        string `json:"title"`
+	PagePath     string `json:"pagepath"`
+	ThumbMediaID string `json:"thumb_media_id"`
+}
+
+/*CustomerMessage 客服消息 */
+type CustomerMessage struct {
+	ToUser          string                   `json:"touser"`
+	MsgType         message.MsgType          `json:"msgtype"`
+	Text            *CustomerText            `json:"text,omitempty"`
+	Image           *CustomerImage           `json:"image,omitempty"`
+	Link            *CustomerLink            `json:"link,omitempty"`
+	MiniProgramPage *CustomerMiniProgramPage `json:"miniprogrampage,omitempty"`
+}
+
+/*NewCustomerText 文本客服消息 */
+func NewCustomerText(toUser, content string) *CustomerMessage {
+	return &CustomerMessage{
+		ToUser:  toUser,
+		MsgType: message.MsgTypeText,
+		Text:    &CustomerText{Content: content},
+	}
+}
+
+/*NewCustomerImage 图片客服消息,mediaID通过UploadTempMedia获得 */
+func NewCustomerImage(toUser, mediaID string) *CustomerMessage {
+	return &CustomerMessage{
+		ToUser:  toUser,
+		MsgType: message.MsgTypeImage,
+		Image:   &CustomerImage{MediaID: mediaID},
+	}
+}
+
+/*NewCustomerLink 图文链接客服消息 */
+func NewCustomerLink(toUser string, link *CustomerLink) *CustomerMessage {
+	return &CustomerMessage{
+		ToUser:  toUser,
+		MsgType: message.MsgTypeLink,
+		Link:    link,
+	}
+}
+
+/*NewCustomerMiniProgramPage 小程序卡片客服消息 */
+func NewCustomerMiniProgramPage(toUser string, page *CustomerMiniProgramPage) *CustomerMessage {
+	return &CustomerMessage{
+		ToUser:          toUser,
+		MsgType:         message.MsgTypeMiniprogrampage,
+		MiniProgramPage: page,
+	}
+}
+
+/*Validate 校验必填字段 */
+func (m *CustomerMessage) Validate() error {
+	if m.ToUser == "" {
+		return ErrCustomerToUser
+	}
+	switch m.MsgType {
+	case message.MsgTypeText:
+		if m.Text == nil || m.Text.Content == "" {
+			return ErrCustomerContent
+		}
+	case message.MsgTypeImage:
+		if m.Image == nil || m.Image.MediaID == "" {
+			return ErrCustomerMediaID
+		}
+	case message.MsgTypeLink:
+		if m.Link == nil || m.Link.Title == "" || m.Link.Description == "" ||
+			m.Link.URL == "" || m.Link.ThumbURL == "" {
+			return ErrCustomerLink
+		}
+	case message.MsgTypeMiniprogrampage:
+		if m.MiniProgramPage == nil || m.MiniProgramPage.Title == "" ||
+			m.MiniProgramPage.PagePath == "" || m.MiniProgramPage.ThumbMediaID == "" {
+			return ErrCustomerMiniProgramPage
+		}
+	default:
+		return ErrCustomerMsgType
+	}
+	return nil
+}
+
+/*ToMap 转换为请求参数 */
+func (m *CustomerMessage) ToMap() util.Map {
+	p := util.Map{}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	if err = json.Unmarshal(b, &p); err != nil {
+		return nil
+	}
+	return p
+}
+
+/*CustomerService 小程序客服消息 */
+type CustomerService struct {
+	*Program
+}
+
+func newCustomerService(program *Program) interface{} {
+	return &CustomerService{
+		Program: program,
+	}
+}
+
+/*NewCustomerService NewCustomerService */
+func NewCustomerService(config *core.Config) *CustomerService {
+	return newCustomerService(NewMiniProgram(config)).(*CustomerService)
+}
+
+/*Send 发送客服消息
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/message/custom/send?access_token=ACCESS_TOKEN
+*/
+func (c *CustomerService) Send(msg *CustomerMessage) core.Responder {
+	log.Debug("CustomerService|Send", msg)
+	if err := msg.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	key := c.accessToken.GetToken().KeyMap()
+	return core.PostJSON(Link(customSend), key, msg.ToMap())
+}
+
+/*Typing 下发客服当前输入状态给用户
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/message/custom/typing?access_token=ACCESS_TOKEN
+参数	必填	说明
+touser	是	用户的 OpenID
+command	是	Typing:对用户下发"正在输入"状态,CancelTyping:取消对用户的"正在输入"状态
+*/
+func (c *CustomerService) Typing(toUser string, typing bool) core.Responder {
+	command := "CancelTyping"
+	if typing {
+		command = "Typing"
+	}
+	key := c.accessToken.GetToken().KeyMap()
+	return core.PostJSON(Link(customTyping), key, util.Map{
+		"touser":  toUser,
+		"command": command,
+	})
+}
+
+/*UploadTempMedia 把媒体文件上传到微信服务器,目前仅支持图片,用于发送客服消息
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/media/upload?access_token=ACCESS_TOKEN&type=TYPE
+成功:
+{"type":"image","media_id":"MEDIA_ID","created_at":"xxx"}
+*/
+func (c *CustomerService) UploadTempMedia(filePath string) core.Responder {
+	log.Debug("CustomerService|UploadTempMedia", filePath)
+	p := c.accessToken.GetToken().KeyMap()
+	p.Set("type", "image")
+	return core.Upload(Link(mediaUpload), p, util.Map{
+		"media": filePath,
+	})
+}
+
+/*GetTempMedia 获取客服消息内的临时素材,即下载临时的多媒体文件
+接口地址:
+GET https://api.weixin.qq.com/cgi-bin/media/get?access_token=ACCESS_TOKEN&media_id=MEDIA_ID
+*/
+func (c *CustomerService) GetTempMedia(mediaID string) core.Responder {
+	p := c.accessToken.GetToken().KeyMap()
+	p.Set("media_id", mediaID)
+	return core.Get(Link(mediaGet), p)
+}
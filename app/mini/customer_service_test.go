@@ -0,0 +1,33 @@
+package mini_test
+
+import (
+	"testing"
+
+	"github.com/godcong/wego/app/mini"
+)
+
+// TestCustomerMessage_Validate ...
+func TestCustomerMessage_Validate(t *testing.T) {
+	msg := mini.NewCustomerMiniProgramPage("oE_gl0Yr54fUjBhU5nBlP4hS2efo", &mini.CustomerMiniProgramPage{
+		Title:        "最新文章",
+		PagePath:     "pages/index/index?foo=bar",
+		ThumbMediaID: "LWOqgv64HBvdT_fjOzJLfsGydEGz6eRq2T6tZA2D2T2V9pGFOu8x_BF2xEXfWCmI",
+	})
+	if err := msg.Validate(); err != nil {
+		t.Error(err)
+	}
+	m := msg.ToMap()
+	if m.GetString("msgtype") != "miniprogrampage" || !m.Has("miniprogrampage") || m.Has("text") {
+		t.Error(m)
+	}
+
+	if err := mini.NewCustomerText("oE_gl0Yr54fUjBhU5nBlP4hS2efo", "").Validate(); err != mini.ErrCustomerContent {
+		t.Error(err)
+	}
+	if err := mini.NewCustomerImage("", "media").Validate(); err != mini.ErrCustomerToUser {
+		t.Error(err)
+	}
+	if err := mini.NewCustomerLink("oE_gl0Yr54fUjBhU5nBlP4hS2efo", &mini.CustomerLink{Title: "最新文章"}).Validate(); err != mini.ErrCustomerLink {
+		t.Error(err)
+	}
+}
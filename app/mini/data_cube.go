@@ -1,7 +1,12 @@
 package mini
 
 import (
+	"encoding/json"
+	"strconv"
+	"time"
+
 	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
 	"github.com/godcong/wego/util"
 )
 
@@ -143,3 +148,417 @@ func (d *DataCube) MonthlyRetainInfo(from, to string) core.Responder {
 func (d *DataCube) VisitPage(from, to string) core.Responder {
 	return d.query(Link(datacubeGetweanalysisappidvisitpage), from, to)
 }
+
+// DataCubeDateLayout 数据分析接口日期格式
+const DataCubeDateLayout = "20060102"
+
+/*DatePeriod 数据分析接口的查询周期 */
+type DatePeriod int
+
+/*DatePeriod types */
+const (
+	DatePeriodDaily   DatePeriod = iota //限定查询1天
+	DatePeriodWeekly                    //限定查询一个自然周
+	DatePeriodMonthly                   //限定查询一个自然月
+)
+
+/*DateRange 单次请求的日期范围 */
+type DateRange struct {
+	Begin time.Time
+	End   time.Time
+}
+
+/*SplitDateRange 按查询周期将日期范围拆分为接口可接受的多个范围
+daily:按天拆分;weekly:begin须为周一,end须为周日;monthly:begin须为月初,end须为月末;end不能晚于昨日 */
+func SplitDateRange(begin, end time.Time, period DatePeriod) ([]DateRange, error) {
+	begin, end = truncateDate(begin), truncateDate(end)
+	if begin.After(end) {
+		return nil, ErrDateRange
+	}
+	if !end.Before(truncateDate(time.Now())) {
+		return nil, ErrDateAfterYesterday
+	}
+	var ranges []DateRange
+	switch period {
+	case DatePeriodDaily:
+		for d := begin; !d.After(end); d = d.AddDate(0, 0, 1) {
+			ranges = append(ranges, DateRange{Begin: d, End: d})
+		}
+	case DatePeriodWeekly:
+		if begin.Weekday() != time.Monday || end.Weekday() != time.Sunday {
+			return nil, ErrDateWeekly
+		}
+		for d := begin; d.Before(end); d = d.AddDate(0, 0, 7) {
+			ranges = append(ranges, DateRange{Begin: d, End: d.AddDate(0, 0, 6)})
+		}
+	case DatePeriodMonthly:
+		if begin.Day() != 1 || end.AddDate(0, 0, 1).Day() != 1 {
+			return nil, ErrDateMonthly
+		}
+		for d := begin; d.Before(end); d = d.AddDate(0, 1, 0) {
+			ranges = append(ranges, DateRange{Begin: d, End: d.AddDate(0, 1, -1)})
+		}
+	default:
+		return nil, ErrDateRange
+	}
+	return ranges, nil
+}
+
+func truncateDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+/*SummaryTrend 概况趋势 */
+type SummaryTrend struct {
+	RefDate    string `json:"ref_date"`
+	VisitTotal int64  `json:"visit_total"`
+	SharePV    int64  `json:"share_pv"`
+	ShareUV    int64  `json:"share_uv"`
+}
+
+/*VisitTrend 访问趋势 */
+type VisitTrend struct {
+	RefDate         string  `json:"ref_date"`
+	SessionCnt      int64   `json:"session_cnt"`
+	VisitPV         int64   `json:"visit_pv"`
+	VisitUV         int64   `json:"visit_uv"`
+	VisitUVNew      int64   `json:"visit_uv_new"`
+	StayTimeUV      float64 `json:"stay_time_uv"`
+	StayTimeSession float64 `json:"stay_time_session"`
+	VisitDepth      float64 `json:"visit_depth"`
+}
+
+/*RetainItem 留存数据,key为0时表示当天,1表示1天后,依此类推 */
+type RetainItem struct {
+	Key   int   `json:"key"`
+	Value int64 `json:"value"`
+}
+
+/*RetainInfo 访问留存 */
+type RetainInfo struct {
+	RefDate    string        `json:"ref_date"`
+	VisitUVNew []*RetainItem `json:"visit_uv_new"`
+	VisitUV    []*RetainItem `json:"visit_uv"`
+}
+
+/*DistributionItem 访问分布项 */
+type DistributionItem struct {
+	Key                 int   `json:"key"`
+	Value               int64 `json:"value"`
+	AccessSourceVisitUV int64 `json:"access_source_visit_uv,omitempty"`
+}
+
+/*Distribution 分布类型,index为access_source_session_cnt,access_staytime_info,access_depth_info */
+type Distribution struct {
+	Index    string              `json:"index"`
+	ItemList []*DistributionItem `json:"item_list"`
+}
+
+/*VisitDistribution 访问分布 */
+type VisitDistribution struct {
+	RefDate string          `json:"ref_date"`
+	List    []*Distribution `json:"list"`
+}
+
+/*PageVisit 页面访问数据 */
+type PageVisit struct {
+	PagePath       string  `json:"page_path"`
+	PageVisitPV    int64   `json:"page_visit_pv"`
+	PageVisitUV    int64   `json:"page_visit_uv"`
+	PageStayTimePV float64 `json:"page_staytime_pv"`
+	EntryPagePV    int64   `json:"entrypage_pv"`
+	ExitPagePV     int64   `json:"exitpage_pv"`
+	PageSharePV    int64   `json:"page_share_pv"`
+	PageShareUV    int64   `json:"page_share_uv"`
+}
+
+/*VisitPageInfo 访问页面 */
+type VisitPageInfo struct {
+	RefDate string       `json:"ref_date"`
+	List    []*PageVisit `json:"list"`
+}
+
+/*PortraitItem 用户画像分布项 */
+type PortraitItem struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+/*PortraitData 用户画像各维度分布 */
+type PortraitData struct {
+	Index     int             `json:"index"`
+	Province  []*PortraitItem `json:"province"`
+	City      []*PortraitItem `json:"city"`
+	Genders   []*PortraitItem `json:"genders"`
+	Platforms []*PortraitItem `json:"platforms"`
+	Devices   []*PortraitItem `json:"devices"`
+	Ages      []*PortraitItem `json:"ages"`
+}
+
+/*UserPortraitInfo 用户画像 */
+type UserPortraitInfo struct {
+	RefDate    string        `json:"ref_date"`
+	VisitUVNew *PortraitData `json:"visit_uv_new"`
+	VisitUV    *PortraitData `json:"visit_uv"`
+}
+
+/*GetSummaryTrend 概况趋势,按天拆分请求 */
+func (d *DataCube) GetSummaryTrend(begin, end time.Time) ([]*SummaryTrend, error) {
+	var list []*SummaryTrend
+	err := d.fetch(datacubeGetweanalysisappiddailysummarytrend, begin, end, DatePeriodDaily, func(data []byte) error {
+		var v struct {
+			List []*SummaryTrend `json:"list"`
+		}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		list = append(list, v.List...)
+		return nil
+	})
+	return list, err
+}
+
+/*GetDailyVisitTrend 日趋势,按天拆分请求 */
+func (d *DataCube) GetDailyVisitTrend(begin, end time.Time) ([]*VisitTrend, error) {
+	return d.fetchVisitTrend(datacubeGetweanalysisappiddailyvisittrend, begin, end, DatePeriodDaily)
+}
+
+/*GetWeeklyVisitTrend 周趋势,按自然周拆分请求 */
+func (d *DataCube) GetWeeklyVisitTrend(begin, end time.Time) ([]*VisitTrend, error) {
+	return d.fetchVisitTrend(datacubeGetweanalysisappidweeklyvisittrend, begin, end, DatePeriodWeekly)
+}
+
+/*GetMonthlyVisitTrend 月趋势,按自然月拆分请求 */
+func (d *DataCube) GetMonthlyVisitTrend(begin, end time.Time) ([]*VisitTrend, error) {
+	return d.fetchVisitTrend(datacubeGetweanalysisappidmonthlyvisittrend, begin, end, DatePeriodMonthly)
+}
+
+/*GetDailyRetainInfo 日留存,按天拆分请求 */
+func (d *DataCube) GetDailyRetainInfo(begin, end time.Time) ([]*RetainInfo, error) {
+	return d.fetchRetainInfo(datacubeGetweanalysisappiddailyretaininfo, begin, end, DatePeriodDaily)
+}
+
+/*GetWeeklyRetainInfo 周留存,按自然周拆分请求 */
+func (d *DataCube) GetWeeklyRetainInfo(begin, end time.Time) ([]*RetainInfo, error) {
+	return d.fetchRetainInfo(datacubeGetweanalysisappidweeklyretaininfo, begin, end, DatePeriodWeekly)
+}
+
+/*GetMonthlyRetainInfo 月留存,按自然月拆分请求 */
+func (d *DataCube) GetMonthlyRetainInfo(begin, end time.Time) ([]*RetainInfo, error) {
+	return d.fetchRetainInfo(datacubeGetweanalysisappidmonthlyretaininfo, begin, end, DatePeriodMonthly)
+}
+
+/*GetVisitDistribution 访问分布,按天拆分请求 */
+func (d *DataCube) GetVisitDistribution(begin, end time.Time) ([]*VisitDistribution, error) {
+	var list []*VisitDistribution
+	err := d.fetch(datacubeGetweanalysisappidvisitdistribution, begin, end, DatePeriodDaily, func(data []byte) error {
+		var v VisitDistribution
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		list = append(list, &v)
+		return nil
+	})
+	return list, err
+}
+
+/*GetVisitPage 访问页面,按天拆分请求 */
+func (d *DataCube) GetVisitPage(begin, end time.Time) ([]*VisitPageInfo, error) {
+	var list []*VisitPageInfo
+	err := d.fetch(datacubeGetweanalysisappidvisitpage, begin, end, DatePeriodDaily, func(data []byte) error {
+		var v VisitPageInfo
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		list = append(list, &v)
+		return nil
+	})
+	return list, err
+}
+
+/*GetUserPortrait 用户画像,查询最近1/7/30天数据,不拆分请求 */
+func (d *DataCube) GetUserPortrait(begin, end time.Time) (*UserPortraitInfo, error) {
+	begin, end = truncateDate(begin), truncateDate(end)
+	if !end.Before(truncateDate(time.Now())) {
+		return nil, ErrDateAfterYesterday
+	}
+	switch end.Sub(begin) / (24 * time.Hour) {
+	case 0, 6, 29:
+	default:
+		return nil, ErrPortraitDateRange
+	}
+	resp := d.query(Link(datacubeGetweanalysisappiduserportrait), begin.Format(DataCubeDateLayout), end.Format(DataCubeDateLayout))
+	data, err := resultData(resp)
+	if err != nil {
+		return nil, err
+	}
+	var v UserPortraitInfo
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (d *DataCube) fetchVisitTrend(api string, begin, end time.Time, period DatePeriod) ([]*VisitTrend, error) {
+	var list []*VisitTrend
+	err := d.fetch(api, begin, end, period, func(data []byte) error {
+		var v struct {
+			List []*VisitTrend `json:"list"`
+		}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		list = append(list, v.List...)
+		return nil
+	})
+	return list, err
+}
+
+func (d *DataCube) fetchRetainInfo(api string, begin, end time.Time, period DatePeriod) ([]*RetainInfo, error) {
+	var list []*RetainInfo
+	err := d.fetch(api, begin, end, period, func(data []byte) error {
+		var v RetainInfo
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		list = append(list, &v)
+		return nil
+	})
+	return list, err
+}
+
+func (d *DataCube) fetch(api string, begin, end time.Time, period DatePeriod, fn func(data []byte) error) error {
+	ranges, err := SplitDateRange(begin, end, period)
+	if err != nil {
+		return err
+	}
+	for _, r := range ranges {
+		log.Debug("DataCube|fetch", api, r.Begin, r.End)
+		resp := d.query(Link(api), r.Begin.Format(DataCubeDateLayout), r.End.Format(DataCubeDateLayout))
+		data, err := resultData(resp)
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxPerformanceDays 性能数据查询最大跨度天数
+const MaxPerformanceDays = 30
+
+/*PerformanceParam 性能数据查询维度 */
+type PerformanceParam struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+/*GetPerformanceData 获取小程序启动性能,运行性能等数据
+接口地址:
+POST https://api.weixin.qq.com/wxa/business/performance/boot?access_token=ACCESS_TOKEN
+参数	必填	说明
+time	是	开始和结束日期的时间戳，时间跨度不能超过30天
+module	是	查询数据的类型,如10016:打开率,10017:启动各阶段耗时,10021:页面切换耗时,10022:内存指标,10023:内存异常
+params	是	查询条件,如networktype,device_level,device
+*/
+func (d *DataCube) GetPerformanceData(begin, end time.Time, module string, params ...*PerformanceParam) core.Responder {
+	if begin.After(end) || end.Sub(begin) > MaxPerformanceDays*24*time.Hour {
+		return core.Err(nil, ErrPerformanceDateRange)
+	}
+	if params == nil {
+		params = []*PerformanceParam{}
+	}
+	token := d.accessToken.GetToken()
+	return core.PostJSON(Link(wxaBusinessPerformanceBoot), token.KeyMap(), util.Map{
+		"time": util.Map{
+			"begin_timestamp": begin.Unix(),
+			"end_timestamp":   end.Unix(),
+		},
+		"module": module,
+		"params": params,
+	})
+}
+
+/*RealtimeLogLevel 实时日志等级 */
+type RealtimeLogLevel int
+
+/*RealtimeLogLevel types */
+const (
+	RealtimeLogLevelInfo  RealtimeLogLevel = 2
+	RealtimeLogLevelWarn  RealtimeLogLevel = 4
+	RealtimeLogLevelError RealtimeLogLevel = 8
+)
+
+/*RealtimeLogSearchOption 实时日志查询参数,Begin与End须为同一天且在最近7天内 */
+type RealtimeLogSearchOption struct {
+	Begin     time.Time
+	End       time.Time
+	Start     int
+	Limit     int
+	TraceID   string
+	URL       string
+	ID        string
+	FilterMsg string
+	Level     RealtimeLogLevel
+}
+
+/*Validate 校验参数 */
+func (o *RealtimeLogSearchOption) Validate() error {
+	if o.Begin.IsZero() || o.End.IsZero() || o.Begin.After(o.End) ||
+		o.Begin.Format(DataCubeDateLayout) != o.End.Format(DataCubeDateLayout) {
+		return ErrRealtimeLogDate
+	}
+	if time.Since(truncateDate(o.Begin)) > 7*24*time.Hour {
+		return ErrRealtimeLogDate
+	}
+	return nil
+}
+
+/*RealtimeLogSearch 实时日志查询
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/userlog/userlog_search?access_token=ACCESS_TOKEN
+参数	必填	说明
+date	是	YYYYMMDD格式的日期，仅支持最近7天
+begintime	是	开始时间，必须是date指定日期的时间
+endtime	是	结束时间，必须是date指定日期的时间
+start	否	开始返回的数据下标，用作分页，默认为0
+limit	否	返回的数据条数，用作分页，默认为20
+traceId	否	小程序启动的唯一ID
+url	否	小程序页面路径
+id	否	用户微信号或者OpenId
+filterMsg	否	开发者通过setFilterMsg/addFilterMsg指定的filterMsg字段
+level	否	日志等级，2(Info)、4(Warn)、8(Error)
+*/
+func (d *DataCube) RealtimeLogSearch(option *RealtimeLogSearchOption) core.Responder {
+	if err := option.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	p := d.accessToken.GetToken().KeyMap()
+	p.Set("date", option.Begin.Format(DataCubeDateLayout))
+	p.Set("begintime", strconv.FormatInt(option.Begin.Unix(), 10))
+	p.Set("endtime", strconv.FormatInt(option.End.Unix(), 10))
+	if option.Start > 0 {
+		p.Set("start", strconv.Itoa(option.Start))
+	}
+	if option.Limit > 0 {
+		p.Set("limit", strconv.Itoa(option.Limit))
+	}
+	if option.Level > 0 {
+		p.Set("level", strconv.Itoa(int(option.Level)))
+	}
+	for k, v := range map[string]string{
+		"traceId":   option.TraceID,
+		"url":       option.URL,
+		"id":        option.ID,
+		"filterMsg": option.FilterMsg,
+	} {
+		if v != "" {
+			p.Set(k, v)
+		}
+	}
+	return core.Get(Link(wxaapiUserLogSearch), p)
+}
@@ -0,0 +1,58 @@
+package mini_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godcong/wego/app/mini"
+)
+
+func date(s string) time.Time {
+	t, _ := time.ParseInLocation(mini.DataCubeDateLayout, s, time.Local)
+	return t
+}
+
+// TestSplitDateRange ...
+func TestSplitDateRange(t *testing.T) {
+	r, err := mini.SplitDateRange(date("20170306"), date("20170310"), mini.DatePeriodDaily)
+	if err != nil || len(r) != 5 || r[4].Begin.Format(mini.DataCubeDateLayout) != "20170310" {
+		t.Error(r, err)
+	}
+
+	r, err = mini.SplitDateRange(date("20170306"), date("20170319"), mini.DatePeriodWeekly)
+	if err != nil || len(r) != 2 || r[1].Begin.Format(mini.DataCubeDateLayout) != "20170313" || r[1].End.Format(mini.DataCubeDateLayout) != "20170319" {
+		t.Error(r, err)
+	}
+	if _, err = mini.SplitDateRange(date("20170307"), date("20170312"), mini.DatePeriodWeekly); err != mini.ErrDateWeekly {
+		t.Error(err)
+	}
+
+	r, err = mini.SplitDateRange(date("20170101"), date("20170331"), mini.DatePeriodMonthly)
+	if err != nil || len(r) != 3 || r[1].End.Format(mini.DataCubeDateLayout) != "20170228" {
+		t.Error(r, err)
+	}
+	if _, err = mini.SplitDateRange(date("20170201"), date("20170227"), mini.DatePeriodMonthly); err != mini.ErrDateMonthly {
+		t.Error(err)
+	}
+
+	if _, err = mini.SplitDateRange(date("20170310"), date("20170306"), mini.DatePeriodDaily); err != mini.ErrDateRange {
+		t.Error(err)
+	}
+	if _, err = mini.SplitDateRange(time.Now().AddDate(0, 0, -1), time.Now(), mini.DatePeriodDaily); err != mini.ErrDateAfterYesterday {
+		t.Error(err)
+	}
+}
+
+// TestRealtimeLogSearchOption_Validate ...
+func TestRealtimeLogSearchOption_Validate(t *testing.T) {
+	now := time.Now()
+	begin := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	opt := &mini.RealtimeLogSearchOption{Begin: begin, End: begin.Add(time.Hour)}
+	if err := opt.Validate(); err != nil {
+		t.Error(err)
+	}
+	opt.End = begin.AddDate(0, 0, 1)
+	if err := opt.Validate(); err != mini.ErrRealtimeLogDate {
+		t.Error(err)
+	}
+}
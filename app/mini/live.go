@@ -0,0 +1,513 @@
+package mini
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+/*LiveType 直播类型 */
+type LiveType int
+
+/*LiveType types */
+const (
+	LiveTypePhone LiveType = 0 //手机直播
+	LiveTypePush  LiveType = 1 //推流
+)
+
+/*LiveStatus 直播间状态 */
+type LiveStatus int
+
+/*LiveStatus types */
+const (
+	LiveStatusLiving   LiveStatus = 101 //直播中
+	LiveStatusNotStart LiveStatus = 102 //未开始
+	LiveStatusEnded    LiveStatus = 103 //已结束
+	LiveStatusBanned   LiveStatus = 104 //禁播
+	LiveStatusPaused   LiveStatus = 105 //暂停
+	LiveStatusError    LiveStatus = 106 //异常
+	LiveStatusExpired  LiveStatus = 107 //已过期
+)
+
+/*LivePriceType 商品价格类型 */
+type LivePriceType int
+
+/*LivePriceType types */
+const (
+	LivePriceTypeFixed    LivePriceType = 1 //一口价,只需填price
+	LivePriceTypeRange    LivePriceType = 2 //价格区间,price为左边界,price2为右边界
+	LivePriceTypeDiscount LivePriceType = 3 //显示折扣价,price为原价,price2为现价
+)
+
+/*LiveGoodsStatus 商品审核状态 */
+type LiveGoodsStatus int
+
+/*LiveGoodsStatus types */
+const (
+	LiveGoodsStatusUnaudited LiveGoodsStatus = 0 //未审核
+	LiveGoodsStatusAuditing  LiveGoodsStatus = 1 //审核中
+	LiveGoodsStatusApproved  LiveGoodsStatus = 2 //审核通过
+	LiveGoodsStatusRejected  LiveGoodsStatus = 3 //审核驳回
+)
+
+/*LiveRole 直播成员角色 */
+type LiveRole int
+
+/*LiveRole types */
+const (
+	LiveRoleAdmin    LiveRole = 1 //管理员
+	LiveRoleAnchor   LiveRole = 2 //主播
+	LiveRoleOperator LiveRole = 3 //运营者
+)
+
+/*直播间时间限制 */
+const (
+	LiveRoomMinDuration = 30 * time.Minute     //直播时长最短30分钟
+	LiveRoomMaxDuration = 24 * time.Hour       //直播时长最长24小时
+	LiveRoomMinLeadTime = 10 * time.Minute     //开播时间需在10分钟后
+	LiveRoomMaxLeadTime = 180 * 24 * time.Hour //开播时间不能在6个月后
+)
+
+// MaxLiveRoomGoods 单次导入直播间的商品数量上限
+const MaxLiveRoomGoods = 200
+
+/*LiveRoom 创建直播间参数,图片均为通过UploadImage上传的media_id */
+type LiveRoom struct {
+	Name            string   `json:"name"`
+	CoverImg        string   `json:"coverImg"`
+	StartTime       int64    `json:"startTime"`
+	EndTime         int64    `json:"endTime"`
+	AnchorName      string   `json:"anchorName"`
+	AnchorWechat    string   `json:"anchorWechat"`
+	SubAnchorWechat string   `json:"subAnchorWechat,omitempty"`
+	CreaterWechat   string   `json:"createrWechat,omitempty"`
+	ShareImg        string   `json:"shareImg"`
+	FeedsImg        string   `json:"feedsImg"`
+	IsFeedsPublic   int      `json:"isFeedsPublic"`
+	Type            LiveType `json:"type"`
+	CloseLike       int      `json:"closeLike"`
+	CloseGoods      int      `json:"closeGoods"`
+	CloseComment    int      `json:"closeComment"`
+	CloseReplay     int      `json:"closeReplay"`
+	CloseShare      int      `json:"closeShare"`
+	CloseKf         int      `json:"closeKf"`
+}
+
+/*Validate 校验必填字段及直播时间 */
+func (r *LiveRoom) Validate() error {
+	if r.Name == "" || r.AnchorName == "" || r.AnchorWechat == "" ||
+		r.CoverImg == "" || r.ShareImg == "" || r.FeedsImg == "" {
+		return ErrLiveRoomRequired
+	}
+	start, end := time.Unix(r.StartTime, 0), time.Unix(r.EndTime, 0)
+	lead, duration := time.Until(start), end.Sub(start)
+	if lead < LiveRoomMinLeadTime || lead > LiveRoomMaxLeadTime ||
+		duration < LiveRoomMinDuration || duration > LiveRoomMaxDuration {
+		return ErrLiveRoomTime
+	}
+	return nil
+}
+
+/*LiveRoomGoods 直播间商品 */
+type LiveRoomGoods struct {
+	CoverImg        string        `json:"cover_img"`
+	URL             string        `json:"url"`
+	Name            string        `json:"name"`
+	Price           int64         `json:"price"`
+	Price2          int64         `json:"price2"`
+	PriceType       LivePriceType `json:"price_type"`
+	GoodsID         int64         `json:"goods_id"`
+	ThirdPartyAppid string        `json:"third_party_appid"`
+}
+
+/*LiveRoomInfo 直播间信息 */
+type LiveRoomInfo struct {
+	Name          string           `json:"name"`
+	RoomID        int64            `json:"roomid"`
+	CoverImg      string           `json:"cover_img"`
+	ShareImg      string           `json:"share_img"`
+	FeedsImg      string           `json:"feeds_img"`
+	LiveStatus    LiveStatus       `json:"live_status"`
+	StartTime     int64            `json:"start_time"`
+	EndTime       int64            `json:"end_time"`
+	AnchorName    string           `json:"anchor_name"`
+	Goods         []*LiveRoomGoods `json:"goods"`
+	LiveType      LiveType         `json:"live_type"`
+	CloseLike     int              `json:"close_like"`
+	CloseGoods    int              `json:"close_goods"`
+	CloseComment  int              `json:"close_comment"`
+	CloseKf       int              `json:"close_kf"`
+	CloseReplay   int              `json:"close_replay"`
+	IsFeedsPublic int              `json:"is_feeds_public"`
+	CreaterOpenid string           `json:"creater_openid"`
+}
+
+/*LiveRoomList 直播间列表 */
+type LiveRoomList struct {
+	RoomInfo []*LiveRoomInfo `json:"room_info"`
+	Total    int             `json:"total"`
+}
+
+/*LiveReplay 直播回放 */
+type LiveReplay struct {
+	ExpireTime string `json:"expire_time"`
+	CreateTime string `json:"create_time"`
+	MediaURL   string `json:"media_url"`
+}
+
+/*LiveReplayList 直播回放列表 */
+type LiveReplayList struct {
+	LiveReplay []*LiveReplay `json:"live_replay"`
+	Total      int           `json:"total"`
+}
+
+/*LiveRoomCreated 创建直播间结果,qrcode_url仅在主播未实名认证时返回 */
+type LiveRoomCreated struct {
+	RoomID    int64  `json:"roomId"`
+	QRCodeURL string `json:"qrcode_url"`
+}
+
+/*LiveGoods 商品库商品,价格单位为元 */
+type LiveGoods struct {
+	GoodsID         int64           `json:"goodsId,omitempty"`
+	CoverImgURL     string          `json:"coverImgUrl,omitempty"`
+	Name            string          `json:"name,omitempty"`
+	PriceType       LivePriceType   `json:"priceType,omitempty"`
+	Price           float64         `json:"price,omitempty"`
+	Price2          float64         `json:"price2,omitempty"`
+	URL             string          `json:"url,omitempty"`
+	AuditStatus     LiveGoodsStatus `json:"audit_status,omitempty"`
+	ThirdPartyTag   int             `json:"thirdPartyTag,omitempty"`
+	ThirdPartyAppid string          `json:"thirdPartyAppid,omitempty"`
+}
+
+/*Validate 校验添加商品的必填字段 */
+func (g *LiveGoods) Validate() error {
+	if g.CoverImgURL == "" || g.Name == "" || g.URL == "" || g.Price <= 0 {
+		return ErrLiveGoodsRequired
+	}
+	switch g.PriceType {
+	case LivePriceTypeFixed:
+	case LivePriceTypeRange, LivePriceTypeDiscount:
+		if g.Price2 <= 0 {
+			return ErrLiveGoodsPrice
+		}
+	default:
+		return ErrLiveGoodsPrice
+	}
+	return nil
+}
+
+/*LiveGoodsCreated 添加商品并提审的结果 */
+type LiveGoodsCreated struct {
+	GoodsID int64 `json:"goodsId"`
+	AuditID int64 `json:"auditId"`
+}
+
+/*LiveGoodsList 商品列表 */
+type LiveGoodsList struct {
+	Goods []*LiveGoods `json:"goods"`
+	Total int          `json:"total"`
+}
+
+/*LiveMember 直播成员 */
+type LiveMember struct {
+	HeadingImg      string     `json:"headingimg"`
+	Nickname        string     `json:"nickname"`
+	OpenID          string     `json:"openid"`
+	RoleList        []LiveRole `json:"roleList"`
+	UpdateTimestamp string     `json:"updateTimestamp"`
+	Username        string     `json:"username"`
+}
+
+/*LiveMemberList 直播成员列表 */
+type LiveMemberList struct {
+	Total int           `json:"total"`
+	List  []*LiveMember `json:"list"`
+}
+
+/*LiveAssistant 直播间小助手 */
+type LiveAssistant struct {
+	Timestamp int64  `json:"timestamp"`
+	Headimg   string `json:"headimg"`
+	Nickname  string `json:"nickname"`
+	Alias     string `json:"alias"`
+	OpenID    string `json:"openid"`
+}
+
+/*LiveAssistantList 直播间小助手列表 */
+type LiveAssistantList struct {
+	Count    int              `json:"count"`
+	MaxCount int              `json:"maxCount"`
+	List     []*LiveAssistant `json:"list"`
+}
+
+/*LiveAssistantUser 添加小助手参数 */
+type LiveAssistantUser struct {
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+}
+
+/*Live 小程序直播 */
+type Live struct {
+	*Program
+}
+
+func newLive(program *Program) interface{} {
+	return &Live{
+		Program: program,
+	}
+}
+
+/*NewLive NewLive */
+func NewLive(config *core.Config) *Live {
+	return newLive(NewMiniProgram(config)).(*Live)
+}
+
+/*UploadImage 上传直播间封面,分享卡片,商品图片等临时素材,返回media_id
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/media/upload?access_token=ACCESS_TOKEN&type=image
+*/
+func (l *Live) UploadImage(filePath string) (string, error) {
+	log.Debug("Live|UploadImage", filePath)
+	p := l.accessToken.GetToken().KeyMap()
+	p.Set("type", "image")
+	data, err := resultData(core.Upload(Link(mediaUpload), p, util.Map{
+		"media": filePath,
+	}))
+	if err != nil {
+		return "", err
+	}
+	var v struct {
+		MediaID string `json:"media_id"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", err
+	}
+	return v.MediaID, nil
+}
+
+/*CreateRoom 创建直播间
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/room/create?access_token=ACCESS_TOKEN
+*/
+func (l *Live) CreateRoom(room *LiveRoom) (*LiveRoomCreated, error) {
+	if err := room.Validate(); err != nil {
+		return nil, err
+	}
+	var v LiveRoomCreated
+	return &v, l.post(wxaapiBroadcastRoomCreate, room, &v)
+}
+
+/*GetRooms 获取直播间列表
+接口地址:
+POST https://api.weixin.qq.com/wxa/business/getliveinfo?access_token=ACCESS_TOKEN
+参数	必填	说明
+start	是	起始拉取房间，start = 0 表示从第 1 个房间开始拉取
+limit	是	每次拉取的个数上限，不要设置过大，建议 100 以内
+*/
+func (l *Live) GetRooms(start, limit int) (*LiveRoomList, error) {
+	var v LiveRoomList
+	return &v, l.post(wxaBusinessGetLiveInfo, util.Map{
+		"start": start,
+		"limit": limit,
+	}, &v)
+}
+
+/*GetReplays 获取直播间回放
+接口地址:
+POST https://api.weixin.qq.com/wxa/business/getliveinfo?access_token=ACCESS_TOKEN
+*/
+func (l *Live) GetReplays(roomID int64, start, limit int) (*LiveReplayList, error) {
+	var v LiveReplayList
+	return &v, l.post(wxaBusinessGetLiveInfo, util.Map{
+		"action":  "get_replay",
+		"room_id": roomID,
+		"start":   start,
+		"limit":   limit,
+	}, &v)
+}
+
+/*AddGoodsToRoom 直播间导入已审核通过的商品
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/room/addgoods?access_token=ACCESS_TOKEN
+*/
+func (l *Live) AddGoodsToRoom(roomID int64, goodsIDs ...int64) error {
+	if len(goodsIDs) == 0 || len(goodsIDs) > MaxLiveRoomGoods {
+		return ErrLiveGoodsRequired
+	}
+	return l.post(wxaapiBroadcastRoomAddGoods, util.Map{
+		"ids":    goodsIDs,
+		"roomId": roomID,
+	}, nil)
+}
+
+/*AddGoods 商品添加并提审
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/goods/add?access_token=ACCESS_TOKEN
+*/
+func (l *Live) AddGoods(goods *LiveGoods) (*LiveGoodsCreated, error) {
+	if err := goods.Validate(); err != nil {
+		return nil, err
+	}
+	var v LiveGoodsCreated
+	return &v, l.post(wxaapiBroadcastGoodsAdd, util.Map{"goodsInfo": goods}, &v)
+}
+
+/*AuditGoods 重新提交审核,返回auditId
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/goods/audit?access_token=ACCESS_TOKEN
+*/
+func (l *Live) AuditGoods(goodsID int64) (int64, error) {
+	var v struct {
+		AuditID int64 `json:"auditId"`
+	}
+	err := l.post(wxaapiBroadcastGoodsAudit, util.Map{"goodsId": goodsID}, &v)
+	return v.AuditID, err
+}
+
+/*ResetAuditGoods 撤回审核
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/goods/resetaudit?access_token=ACCESS_TOKEN
+*/
+func (l *Live) ResetAuditGoods(auditID, goodsID int64) error {
+	return l.post(wxaapiBroadcastGoodsResetAudit, util.Map{
+		"auditId": auditID,
+		"goodsId": goodsID,
+	}, nil)
+}
+
+/*DeleteGoods 删除商品
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/goods/delete?access_token=ACCESS_TOKEN
+*/
+func (l *Live) DeleteGoods(goodsID int64) error {
+	return l.post(wxaapiBroadcastGoodsDelete, util.Map{"goodsId": goodsID}, nil)
+}
+
+/*UpdateGoods 更新商品,审核通过的商品仅允许更新价格类型与价格,审核中的商品不允许更新
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/goods/update?access_token=ACCESS_TOKEN
+*/
+func (l *Live) UpdateGoods(goods *LiveGoods) error {
+	if goods.GoodsID == 0 {
+		return ErrLiveGoodsRequired
+	}
+	return l.post(wxaapiBroadcastGoodsUpdate, util.Map{"goodsInfo": goods}, nil)
+}
+
+/*GetApprovedGoods 获取商品列表
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/broadcast/goods/getapproved?access_token=ACCESS_TOKEN&offset=1&limit=30&status=99
+*/
+func (l *Live) GetApprovedGoods(status LiveGoodsStatus, offset, limit int) (*LiveGoodsList, error) {
+	var v LiveGoodsList
+	return &v, l.get(wxaapiBroadcastGoodsGetApproved, util.Map{
+		"status": strconv.Itoa(int(status)),
+		"offset": strconv.Itoa(offset),
+		"limit":  strconv.Itoa(limit),
+	}, &v)
+}
+
+/*AddRole 设置成员角色,username为微信号
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/role/addrole?access_token=ACCESS_TOKEN
+*/
+func (l *Live) AddRole(username string, role LiveRole) error {
+	return l.post(wxaapiBroadcastRoleAdd, util.Map{
+		"username": username,
+		"role":     role,
+	}, nil)
+}
+
+/*DeleteRole 解除成员角色
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/role/deleterole?access_token=ACCESS_TOKEN
+*/
+func (l *Live) DeleteRole(username string, role LiveRole) error {
+	return l.post(wxaapiBroadcastRoleDelete, util.Map{
+		"username": username,
+		"role":     role,
+	}, nil)
+}
+
+/*GetRoleList 查询成员列表,role为-1时查询所有角色
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/broadcast/role/getrolelist?access_token=ACCESS_TOKEN
+*/
+func (l *Live) GetRoleList(role LiveRole, offset, limit int, keyword string) (*LiveMemberList, error) {
+	query := util.Map{
+		"role":   strconv.Itoa(int(role)),
+		"offset": strconv.Itoa(offset),
+		"limit":  strconv.Itoa(limit),
+	}
+	if keyword != "" {
+		query.Set("keyword", keyword)
+	}
+	var v LiveMemberList
+	return &v, l.get(wxaapiBroadcastRoleGetList, query, &v)
+}
+
+/*AddAssistant 添加直播间小助手
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/room/addassistant?access_token=ACCESS_TOKEN
+*/
+func (l *Live) AddAssistant(roomID int64, users ...*LiveAssistantUser) error {
+	return l.post(wxaapiBroadcastRoomAddAssistant, util.Map{
+		"roomId": roomID,
+		"users":  users,
+	}, nil)
+}
+
+/*ModifyAssistant 修改直播间小助手昵称
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/room/modifyassistant?access_token=ACCESS_TOKEN
+*/
+func (l *Live) ModifyAssistant(roomID int64, username, nickname string) error {
+	return l.post(wxaapiBroadcastRoomModifyAssistant, util.Map{
+		"roomId":   roomID,
+		"username": username,
+		"nickname": nickname,
+	}, nil)
+}
+
+/*RemoveAssistant 删除直播间小助手
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/broadcast/room/removeassistant?access_token=ACCESS_TOKEN
+*/
+func (l *Live) RemoveAssistant(roomID int64, username string) error {
+	return l.post(wxaapiBroadcastRoomRemoveAssistant, util.Map{
+		"roomId":   roomID,
+		"username": username,
+	}, nil)
+}
+
+/*GetAssistantList 查询直播间小助手
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/broadcast/room/getassistantlist?access_token=ACCESS_TOKEN
+*/
+func (l *Live) GetAssistantList(roomID int64) (*LiveAssistantList, error) {
+	var v LiveAssistantList
+	return &v, l.get(wxaapiBroadcastRoomGetAssistantList, util.Map{
+		"roomId": strconv.FormatInt(roomID, 10),
+	}, &v)
+}
+
+func (l *Live) post(url string, body interface{}, v interface{}) error {
+	log.Debug("Live|post", url, body)
+	key := l.accessToken.GetToken().KeyMap()
+	return unmarshalResult(core.PostJSON(Link(url), key, body), v)
+}
+
+func (l *Live) get(url string, query util.Map, v interface{}) error {
+	log.Debug("Live|get", url, query)
+	key := l.accessToken.GetToken().KeyMap()
+	for k, val := range query {
+		key.Set(k, val)
+	}
+	return unmarshalResult(core.Get(Link(url), key), v)
+}
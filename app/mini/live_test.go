@@ -0,0 +1,52 @@
+package mini_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godcong/wego/app/mini"
+)
+
+// TestLiveRoom_Validate ...
+func TestLiveRoom_Validate(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+	room := &mini.LiveRoom{
+		Name:         "测试直播间",
+		CoverImg:     "hw7zsntcr0rE-RBfBAaF553DqBk-J02UtWsP8VqrUh3tKu3jO_JwEO8n1cWTJ5TN",
+		StartTime:    start.Unix(),
+		EndTime:      start.Add(2 * time.Hour).Unix(),
+		AnchorName:   "zefzhang1",
+		AnchorWechat: "WxgQiao_04",
+		ShareImg:     "hw7zsntcr0rE-RBfBAaF553DqBk-J02UtWsP8VqrUh3tKu3jO_JwEO8n1cWTJ5TN",
+		FeedsImg:     "hw7zsntcr0rE-RBfBAaF553DqBk-J02UtWsP8VqrUh3tKu3jO_JwEO8n1cWTJ5TN",
+	}
+	if err := room.Validate(); err != nil {
+		t.Error(err)
+	}
+	room.EndTime = start.Add(10 * time.Minute).Unix()
+	if err := room.Validate(); err != mini.ErrLiveRoomTime {
+		t.Error(err)
+	}
+	room.FeedsImg = ""
+	if err := room.Validate(); err != mini.ErrLiveRoomRequired {
+		t.Error(err)
+	}
+}
+
+// TestLiveGoods_Validate ...
+func TestLiveGoods_Validate(t *testing.T) {
+	goods := &mini.LiveGoods{
+		CoverImgURL: "ZuYVNKqvHGhZkFfU9wTzgx3E9wW3gTtLZQDrkI7e3VQ",
+		Name:        "TIT茶杯",
+		PriceType:   mini.LivePriceTypeRange,
+		Price:       99.5,
+		URL:         "pages/index/index",
+	}
+	if err := goods.Validate(); err != mini.ErrLiveGoodsPrice {
+		t.Error(err)
+	}
+	goods.Price2 = 150.5
+	if err := goods.Validate(); err != nil {
+		t.Error(err)
+	}
+}
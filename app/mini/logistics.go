@@ -0,0 +1,341 @@
+package mini
+
+import (
+	"strings"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+/*ExpressAddSource 订单来源 */
+type ExpressAddSource int
+
+/*ExpressAddSource types */
+const (
+	ExpressAddSourceMiniProgram ExpressAddSource = 0 //小程序订单
+	ExpressAddSourceApp         ExpressAddSource = 2 //App或H5订单,须填写wx_appid
+)
+
+/*PrinterUpdateType 打印员更新类型 */
+type PrinterUpdateType string
+
+/*PrinterUpdateType types */
+const (
+	PrinterUpdateTypeBind   PrinterUpdateType = "bind"   //绑定
+	PrinterUpdateTypeUnbind PrinterUpdateType = "unbind" //解除绑定
+)
+
+/*ExpressContact 发件人/收件人信息 */
+type ExpressContact struct {
+	Name     string `json:"name"`
+	Tel      string `json:"tel,omitempty"`
+	Mobile   string `json:"mobile,omitempty"`
+	Company  string `json:"company,omitempty"`
+	PostCode string `json:"post_code,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Province string `json:"province"`
+	City     string `json:"city"`
+	Area     string `json:"area"`
+	Address  string `json:"address"`
+}
+
+/*Validate 校验必填字段 */
+func (c *ExpressContact) Validate() error {
+	if c == nil || c.Name == "" || (c.Tel == "" && c.Mobile == "") ||
+		c.Province == "" || c.City == "" || c.Area == "" || c.Address == "" {
+		return ErrExpressContact
+	}
+	return nil
+}
+
+/*ExpressCargoDetail 包裹中的商品 */
+type ExpressCargoDetail struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+/*ExpressCargo 包裹信息 */
+type ExpressCargo struct {
+	Count      int                   `json:"count"`
+	Weight     float64               `json:"weight"`  //单位kg
+	SpaceX     float64               `json:"space_x"` //单位cm
+	SpaceY     float64               `json:"space_y"`
+	SpaceZ     float64               `json:"space_z"`
+	DetailList []*ExpressCargoDetail `json:"detail_list"`
+}
+
+/*Validate 校验必填字段 */
+func (c *ExpressCargo) Validate() error {
+	if c == nil || c.Count <= 0 || c.Weight <= 0 || len(c.DetailList) == 0 {
+		return ErrExpressCargo
+	}
+	return nil
+}
+
+/*ExpressShop 商品信息,会展示到物流服务通知和电子面单中 */
+type ExpressShop struct {
+	WxaPath    string `json:"wxa_path"`
+	ImgURL     string `json:"img_url"`
+	GoodsName  string `json:"goods_name"`
+	GoodsCount int    `json:"goods_count"`
+}
+
+/*ExpressInsured 保价信息 */
+type ExpressInsured struct {
+	UseInsured   int   `json:"use_insured"`   //是否保价,0不保价,1保价
+	InsuredValue int64 `json:"insured_value"` //保价金额,单位分
+}
+
+/*ExpressService 服务类型,可通过GetAllDelivery获取 */
+type ExpressService struct {
+	ServiceType int    `json:"service_type"`
+	ServiceName string `json:"service_name"`
+}
+
+/*ExpressOrder 生成运单参数 */
+type ExpressOrder struct {
+	AddSource    ExpressAddSource `json:"add_source"`
+	WxAppID      string           `json:"wx_appid,omitempty"`
+	OrderID      string           `json:"order_id"`
+	OpenID       string           `json:"openid,omitempty"`
+	DeliveryID   string           `json:"delivery_id"`
+	BizID        string           `json:"biz_id"`
+	CustomRemark string           `json:"custom_remark,omitempty"`
+	TagID        int64            `json:"tagid,omitempty"`
+	ExpectTime   int64            `json:"expect_time,omitempty"`
+	Sender       *ExpressContact  `json:"sender"`
+	Receiver     *ExpressContact  `json:"receiver"`
+	Cargo        *ExpressCargo    `json:"cargo"`
+	Shop         *ExpressShop     `json:"shop"`
+	Insured      *ExpressInsured  `json:"insured"`
+	Service      *ExpressService  `json:"service"`
+}
+
+/*Validate 校验必填字段 */
+func (o *ExpressOrder) Validate() error {
+	if o.OrderID == "" || o.DeliveryID == "" || o.BizID == "" ||
+		(o.AddSource == ExpressAddSourceMiniProgram && o.OpenID == "") {
+		return ErrExpressOrderRequired
+	}
+	if err := o.Sender.Validate(); err != nil {
+		return err
+	}
+	if err := o.Receiver.Validate(); err != nil {
+		return err
+	}
+	if err := o.Cargo.Validate(); err != nil {
+		return err
+	}
+	if o.Service == nil || o.Service.ServiceName == "" {
+		return ErrExpressService
+	}
+	if o.Insured == nil {
+		o.Insured = &ExpressInsured{}
+	}
+	return nil
+}
+
+/*ExpressWaybill 运单标识,用于取消运单,获取运单数据及查询轨迹 */
+type ExpressWaybill struct {
+	OrderID    string `json:"order_id"`
+	OpenID     string `json:"openid,omitempty"`
+	DeliveryID string `json:"delivery_id"`
+	WaybillID  string `json:"waybill_id"`
+}
+
+/*ExpressDelivery 快递公司 */
+type ExpressDelivery struct {
+	DeliveryID   string `json:"delivery_id"`
+	DeliveryName string `json:"delivery_name"`
+}
+
+/*ExpressDeliveryList 快递公司列表 */
+type ExpressDeliveryList struct {
+	Count int                `json:"count"`
+	Data  []*ExpressDelivery `json:"data"`
+}
+
+/*ExpressWaybillData 运单信息 */
+type ExpressWaybillData struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+/*ExpressOrderResult 运单数据,print_html仅在获取运单数据时返回,base64编码 */
+type ExpressOrderResult struct {
+	OrderID            string                `json:"order_id"`
+	DeliveryID         string                `json:"delivery_id,omitempty"`
+	WaybillID          string                `json:"waybill_id"`
+	PrintHTML          string                `json:"print_html,omitempty"`
+	WaybillData        []*ExpressWaybillData `json:"waybill_data"`
+	DeliveryResultCode int                   `json:"delivery_resultcode,omitempty"`
+	DeliveryResultMsg  string                `json:"delivery_resultmsg,omitempty"`
+}
+
+/*ExpressPathItem 轨迹节点 */
+type ExpressPathItem struct {
+	ActionTime int64  `json:"action_time"`
+	ActionType int    `json:"action_type"`
+	ActionMsg  string `json:"action_msg"`
+}
+
+/*ExpressPath 运单轨迹 */
+type ExpressPath struct {
+	OpenID       string             `json:"openid"`
+	DeliveryID   string             `json:"delivery_id"`
+	WaybillID    string             `json:"waybill_id"`
+	PathItemNum  int                `json:"path_item_num"`
+	PathItemList []*ExpressPathItem `json:"path_item_list"`
+}
+
+/*ExpressPrinterList 打印员列表 */
+type ExpressPrinterList struct {
+	Count     int      `json:"count"`
+	OpenID    []string `json:"openid"`
+	TagIDList []string `json:"tagid_list"`
+}
+
+/*Logistics 物流助手
+运单轨迹更新通过add_express_path事件推送,可在Server回调中通过core.Message.DecodeEvent
+解析为*message.AddExpressPathEvent */
+type Logistics struct {
+	*Program
+}
+
+func newLogistics(program *Program) interface{} {
+	return &Logistics{
+		Program: program,
+	}
+}
+
+/*NewLogistics NewLogistics */
+func NewLogistics(config *core.Config) *Logistics {
+	return newLogistics(NewMiniProgram(config)).(*Logistics)
+}
+
+/*GetAllDelivery 获取支持的快递公司列表
+接口地址:
+GET https://api.weixin.qq.com/cgi-bin/express/business/delivery/getall?access_token=ACCESS_TOKEN
+*/
+func (l *Logistics) GetAllDelivery() (*ExpressDeliveryList, error) {
+	var v ExpressDeliveryList
+	if err := l.get(expressDeliveryGetAll, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+/*AddOrder 生成运单
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/express/business/order/add?access_token=ACCESS_TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","order_id":"01234567890123456789","waybill_id":"123456789","waybill_data":[{"key":"SF_bagAddr","value":"广州"}]}
+*/
+func (l *Logistics) AddOrder(order *ExpressOrder) (*ExpressOrderResult, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	var v ExpressOrderResult
+	if err := l.post(expressOrderAdd, order, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+/*CancelOrder 取消运单
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/express/business/order/cancel?access_token=ACCESS_TOKEN
+*/
+func (l *Logistics) CancelOrder(waybill *ExpressWaybill) error {
+	return l.post(expressOrderCancel, waybill, nil)
+}
+
+/*GetOrder 获取运单数据
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/express/business/order/get?access_token=ACCESS_TOKEN
+*/
+func (l *Logistics) GetOrder(waybill *ExpressWaybill) (*ExpressOrderResult, error) {
+	var v ExpressOrderResult
+	if err := l.post(expressOrderGet, waybill, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+/*GetPath 查询运单轨迹
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/express/business/path/get?access_token=ACCESS_TOKEN
+成功:
+{"openid":"OPENID","delivery_id":"SF","waybill_id":"12345678901234567890","path_item_num":1,"path_item_list":[{"action_time":1533052800,"action_type":100001,"action_msg":"快递员已成功取件"}]}
+*/
+func (l *Logistics) GetPath(waybill *ExpressWaybill) (*ExpressPath, error) {
+	var v ExpressPath
+	if err := l.post(expressPathGet, waybill, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+/*GetPrinter 获取打印员列表
+接口地址:
+GET https://api.weixin.qq.com/cgi-bin/express/business/printer/getall?access_token=ACCESS_TOKEN
+*/
+func (l *Logistics) GetPrinter() (*ExpressPrinterList, error) {
+	var v ExpressPrinterList
+	if err := l.get(expressPrinterGetAll, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+/*UpdatePrinter 配置面单打印员
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/express/business/printer/update?access_token=ACCESS_TOKEN
+参数	必填	说明
+openid	是	打印员 openid
+update_type	是	更新类型,bind或unbind
+tagid_list	否	用于平台型小程序设置入驻方的打印员面单打印权限，同一打印员最多支持10个tagid
+*/
+func (l *Logistics) UpdatePrinter(openID string, updateType PrinterUpdateType, tagIDs ...string) error {
+	if updateType != PrinterUpdateTypeBind && updateType != PrinterUpdateTypeUnbind {
+		return ErrPrinterUpdateType
+	}
+	p := util.Map{
+		"openid":      openID,
+		"update_type": updateType,
+	}
+	if tagIDs != nil {
+		p.Set("tagid_list", strings.Join(tagIDs, ","))
+	}
+	return l.post(expressPrinterUpdate, p, nil)
+}
+
+/*GetQuota 获取电子面单余额,仅在使用加盟类快递公司时使用
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/express/business/quota/get?access_token=ACCESS_TOKEN
+成功:
+{"quota_num":210}
+*/
+func (l *Logistics) GetQuota(deliveryID, bizID string) (int, error) {
+	var v struct {
+		QuotaNum int `json:"quota_num"`
+	}
+	err := l.post(expressQuotaGet, util.Map{
+		"delivery_id": deliveryID,
+		"biz_id":      bizID,
+	}, &v)
+	return v.QuotaNum, err
+}
+
+func (l *Logistics) post(url string, body interface{}, v interface{}) error {
+	log.Debug("Logistics|post", url, body)
+	key := l.accessToken.GetToken().KeyMap()
+	return unmarshalResult(core.PostJSON(Link(url), key, body), v)
+}
+
+func (l *Logistics) get(url string, v interface{}) error {
+	log.Debug("Logistics|get", url)
+	key := l.accessToken.GetToken().KeyMap()
+	return unmarshalResult(core.Get(Link(url), key), v)
+}
@@ -0,0 +1,81 @@
+package mini_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/godcong/wego/app/mini"
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+)
+
+// TestExpressOrder_Validate ...
+func TestExpressOrder_Validate(t *testing.T) {
+	contact := &mini.ExpressContact{
+		Name:     "张三",
+		Mobile:   "13580006666",
+		Province: "广东省",
+		City:     "广州市",
+		Area:     "海珠区",
+		Address:  "TIT创意园",
+	}
+	order := &mini.ExpressOrder{
+		OrderID:    "01234567890123456789",
+		OpenID:     "oABC123456",
+		DeliveryID: "SF",
+		BizID:      "xyz",
+		Sender:     contact,
+		Receiver:   contact,
+		Cargo: &mini.ExpressCargo{
+			Count:      1,
+			Weight:     1.2,
+			DetailList: []*mini.ExpressCargoDetail{{Name: "一千零一夜钻石包", Count: 1}},
+		},
+		Service: &mini.ExpressService{ServiceType: 0, ServiceName: "标准快递"},
+	}
+	if err := order.Validate(); err != nil || order.Insured == nil {
+		t.Error(err)
+	}
+	order.Receiver = &mini.ExpressContact{Name: "李四"}
+	if err := order.Validate(); err != mini.ErrExpressContact {
+		t.Error(err)
+	}
+	order.OpenID = ""
+	if err := order.Validate(); err != mini.ErrExpressOrderRequired {
+		t.Error(err)
+	}
+}
+
+// TestMessage_DecodeEvent_AddExpressPath ...
+func TestMessage_DecodeEvent_AddExpressPath(t *testing.T) {
+	data := `<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[fromUser]]></FromUserName><CreateTime>1546924844</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[add_express_path]]></Event><DeliveryID><![CDATA[SF]]></DeliveryID><WayBillId><![CDATA[123456789]]></WayBillId><Version>3</Version><Count>2</Count><Actions><ActionTime>1546924840</ActionTime><ActionType>100001</ActionType><ActionMsg><![CDATA[小哥A揽件成功]]></ActionMsg></Actions><Actions><ActionTime>1546924840</ActionTime><ActionType>200001</ActionType><ActionMsg><![CDATA[到达广州集包地]]></ActionMsg></Actions><OrderId><![CDATA[01234567890123456789]]></OrderId></xml>`
+	msg, err := core.ParseMessage([]byte(data), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt, err := msg.DecodeEvent()
+	e, b := evt.(*message.AddExpressPathEvent)
+	if err != nil || !b || e.WayBillID != "123456789" || len(e.Actions) != 2 || e.Actions[1].ActionType != 200001 {
+		t.Errorf("%+v %v", evt, err)
+	}
+}
+
+// TestExpressPath_Unmarshal ...
+func TestExpressPath_Unmarshal(t *testing.T) {
+	data := `{"openid":"OPENID","delivery_id":"SF","waybill_id":"12345678901234567890","path_item_num":1,"path_item_list":[{"action_time":1533052800,"action_type":100001,"action_msg":"快递员已成功取件"}]}`
+	var path mini.ExpressPath
+	if err := json.Unmarshal([]byte(data), &path); err != nil {
+		t.Fatal(err)
+	}
+	if path.PathItemNum != 1 || len(path.PathItemList) != 1 || path.PathItemList[0].ActionType != 100001 {
+		t.Errorf("%+v", path)
+	}
+	data = `{"errcode":0,"errmsg":"ok","order_id":"01234567890123456789","waybill_id":"123456789","waybill_data":[{"key":"SF_bagAddr","value":"广州"}]}`
+	var order mini.ExpressOrderResult
+	if err := json.Unmarshal([]byte(data), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.WaybillID != "123456789" || len(order.WaybillData) != 1 || order.WaybillData[0].Value != "广州" {
+		t.Errorf("%+v", order)
+	}
+}
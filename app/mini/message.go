@@ -61,10 +61,3 @@ func (m *Message) Send(msg util.Map) core.Responder {
 		msg)
 	return resp
 }
-
-/*MessageSend //TODO
-
- */
-func MessageSend() {
-	//https://api.weixin.qq.com/cgi-bin/message/custom/send
-}
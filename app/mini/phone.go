@@ -0,0 +1,66 @@
+package mini
+
+import (
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+/*Phone 手机号快速验证 */
+type Phone struct {
+	*Program
+}
+
+func newPhone(program *Program) interface{} {
+	return &Phone{
+		Program: program,
+	}
+}
+
+/*NewPhone NewPhone */
+func NewPhone(config *core.Config) *Phone {
+	return newPhone(NewMiniProgram(config)).(*Phone)
+}
+
+/*GetUserPhoneNumber 使用getPhoneNumber返回的code换取用户手机号,code有效期5分钟且只能使用一次
+接口地址:
+POST https://api.weixin.qq.com/wxa/business/getuserphonenumber?access_token=ACCESS_TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","phone_info":{"phoneNumber":"xxxxxx","purePhoneNumber":"xxxxxx","countryCode":86,"watermark":{"timestamp":1637744274,"appid":"xxxx"}}}
+*/
+func (p *Phone) GetUserPhoneNumber(code string) core.Responder {
+	log.Debug("Phone|GetUserPhoneNumber", code)
+	key := p.accessToken.GetToken().KeyMap()
+	return core.PostJSON(Link(wxaBusinessGetUserPhoneNumber), key, util.Map{"code": code})
+}
+
+/*PhoneNumberByCode 使用code获取手机号 */
+func (p *Phone) PhoneNumberByCode(code string) (*PhoneNumber, error) {
+	resp := p.GetUserPhoneNumber(code)
+	if err := resp.Error(); err != nil {
+		return nil, err
+	}
+	return ParsePhoneNumber(resp.Bytes())
+}
+
+/*PhoneNumber 获取手机号,新版本基础库使用code,旧版本使用登录态token解密encryptedData */
+func (p *Phone) PhoneNumber(code, token, encryptedData, iv string) (*PhoneNumber, error) {
+	if code != "" {
+		return p.PhoneNumberByCode(code)
+	}
+	return p.SessionManager().PhoneNumber(token, encryptedData, iv)
+}
+
+/*ParsePhoneNumber 解析getuserphonenumber接口返回 */
+func ParsePhoneNumber(data []byte) (*PhoneNumber, error) {
+	var v struct {
+		PhoneInfo *PhoneNumber `json:"phone_info"`
+	}
+	if err := parseResult(data, &v); err != nil {
+		return nil, err
+	}
+	if v.PhoneInfo == nil {
+		return nil, ErrPhoneInfoEmpty
+	}
+	return v.PhoneInfo, nil
+}
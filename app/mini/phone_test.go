@@ -0,0 +1,18 @@
+package mini_test
+
+import (
+	"testing"
+
+	"github.com/godcong/wego/app/mini"
+)
+
+// TestParsePhoneNumber ...
+func TestParsePhoneNumber(t *testing.T) {
+	phone, err := mini.ParsePhoneNumber([]byte(`{"errcode":0,"errmsg":"ok","phone_info":{"phoneNumber":"13580006666","purePhoneNumber":"13580006666","countryCode":86,"watermark":{"timestamp":1637744274,"appid":"wx1ad61aeef1903b93"}}}`))
+	if err != nil || phone.PurePhoneNumber != "13580006666" || phone.CountryCode != "86" || phone.Watermark.AppID != "wx1ad61aeef1903b93" {
+		t.Error(phone, err)
+	}
+	if _, err := mini.ParsePhoneNumber([]byte(`{"errcode":40029,"errmsg":"invalid code"}`)); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -1,6 +1,8 @@
 package mini
 
 import (
+	"encoding/json"
+
 	"github.com/godcong/wego/cipher"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/util"
@@ -10,7 +12,16 @@ import (
 type NewAble func(program *Program) interface{}
 
 var subLists = util.Map{
-	"AppCode": newAppcode,
+	"AppCode":         newAppcode,
+	"Cloud":           newCloud,
+	"CustomerService": newCustomerService,
+	"DataCube":        newDataCube,
+	"Live":            newLive,
+	"Logistics":       newLogistics,
+	"Phone":           newPhone,
+	"Security":        newSecurity,
+	"SessionManager":  newSessionManager,
+	"Subscribe":       newSubscribe,
 }
 
 /*Program Program */
@@ -42,7 +53,10 @@ func NewMiniProgram(config *core.Config, v ...interface{}) *Program {
 }
 func subInit(payment *Program, p util.Map) *Program {
 	for k, v := range p {
-		if vv, b := v.(NewAble); b {
+		switch vv := v.(type) {
+		case NewAble:
+			payment.Sub[k] = vv(payment)
+		case func(program *Program) interface{}:
 			payment.Sub[k] = vv(payment)
 		}
 	}
@@ -74,6 +88,16 @@ func (p *Program) SetAccessToken(accessToken *core.AccessToken) {
 	p.accessToken = accessToken
 }
 
+// AppCode ...
+func (p *Program) AppCode() *AppCode {
+	obj, b := p.Sub["AppCode"]
+	if !b {
+		obj = newAppcode(p)
+		p.Sub["AppCode"] = obj
+	}
+	return obj.(*AppCode)
+}
+
 // Auth ...
 func (p *Program) Auth() *Auth {
 	obj, b := p.Sub["Auth"]
@@ -84,6 +108,56 @@ func (p *Program) Auth() *Auth {
 	return obj.(*Auth)
 }
 
+// Cloud ...
+func (p *Program) Cloud() *Cloud {
+	obj, b := p.Sub["Cloud"]
+	if !b {
+		obj = newCloud(p)
+		p.Sub["Cloud"] = obj
+	}
+	return obj.(*Cloud)
+}
+
+// CustomerService ...
+func (p *Program) CustomerService() *CustomerService {
+	obj, b := p.Sub["CustomerService"]
+	if !b {
+		obj = newCustomerService(p)
+		p.Sub["CustomerService"] = obj
+	}
+	return obj.(*CustomerService)
+}
+
+// DataCube ...
+func (p *Program) DataCube() *DataCube {
+	obj, b := p.Sub["DataCube"]
+	if !b {
+		obj = newDataCube(p)
+		p.Sub["DataCube"] = obj
+	}
+	return obj.(*DataCube)
+}
+
+// Live ...
+func (p *Program) Live() *Live {
+	obj, b := p.Sub["Live"]
+	if !b {
+		obj = newLive(p)
+		p.Sub["Live"] = obj
+	}
+	return obj.(*Live)
+}
+
+// Logistics ...
+func (p *Program) Logistics() *Logistics {
+	obj, b := p.Sub["Logistics"]
+	if !b {
+		obj = newLogistics(p)
+		p.Sub["Logistics"] = obj
+	}
+	return obj.(*Logistics)
+}
+
 // Message ...
 func (p *Program) Message() *Message {
 	obj, b := p.Sub["Message"]
@@ -94,6 +168,46 @@ func (p *Program) Message() *Message {
 	return obj.(*Message)
 }
 
+// Phone ...
+func (p *Program) Phone() *Phone {
+	obj, b := p.Sub["Phone"]
+	if !b {
+		obj = newPhone(p)
+		p.Sub["Phone"] = obj
+	}
+	return obj.(*Phone)
+}
+
+// Security ...
+func (p *Program) Security() *Security {
+	obj, b := p.Sub["Security"]
+	if !b {
+		obj = newSecurity(p)
+		p.Sub["Security"] = obj
+	}
+	return obj.(*Security)
+}
+
+// SessionManager ...
+func (p *Program) SessionManager() *SessionManager {
+	obj, b := p.Sub["SessionManager"]
+	if !b {
+		obj = newSessionManager(p)
+		p.Sub["SessionManager"] = obj
+	}
+	return obj.(*SessionManager)
+}
+
+// Subscribe ...
+func (p *Program) Subscribe() *Subscribe {
+	obj, b := p.Sub["Subscribe"]
+	if !b {
+		obj = newSubscribe(p)
+		p.Sub["Subscribe"] = obj
+	}
+	return obj.(*Subscribe)
+}
+
 // Template ...
 func (p *Program) Template() *Template {
 	obj, b := p.Sub["Template"]
@@ -108,3 +222,49 @@ func (p *Program) Template() *Template {
 func Link(url string) string {
 	return core.Splice(core.DefaultConfig().GetStringD("domain.mini_program.url", domain), url)
 }
+
+// resultError 接口返回的errcode不为0
+type resultError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// Error ...
+func (e *resultError) Error() string {
+	return e.ErrMsg
+}
+
+// parseResult 检查errcode并将接口返回解析到v中,v为nil时只检查errcode
+func parseResult(data []byte, v interface{}) error {
+	var e resultError
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	if e.ErrCode != 0 {
+		return &e
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// resultData 检查接口返回的errcode,返回原始数据
+func resultData(resp core.Responder) ([]byte, error) {
+	if err := resp.Error(); err != nil {
+		return nil, err
+	}
+	data := resp.Bytes()
+	if err := parseResult(data, nil); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// unmarshalResult 检查errcode并解析接口返回
+func unmarshalResult(resp core.Responder, v interface{}) error {
+	if err := resp.Error(); err != nil {
+		return err
+	}
+	return parseResult(resp.Bytes(), v)
+}
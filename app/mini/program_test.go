@@ -241,3 +241,20 @@ func TestAuth_UserInfo(t *testing.T) {
 	resp := auth.UserInfo("002JXxze2ilgfB0zNmAe2Amsze2JXxzJ", "rCmWuMckRqkw33i+s+NCh32iPdO+yiPS/FWJInan6XUdnXROIC8vXm7clc5NlRMFjI1hPo59eWWeLeLyfZs5lzuzOHASH2VVnwwetAjwbt9KC9v8zWGAZfvlweQWlBtKpSNS0H9dc1bhXafuA763mRq0v01Uq/LAktVAcyd1l/2JCKPhosRSov9F8FTCTt4YL1S4NeYGcjPDb+Mgb9LeRleseMZuziZbKvs66XnPw2ARtrGsiU3uyB4/WZGKERMJll3eRmgYe98F+q4ey0VAz3+Ah5x5NHDfrmxFgm4t3U78VF9q7IB706ULUgMozXJlU5cjsuaVNROXpBmWT/3fHpL3XIWl6U/m7V9o8RiLmmxSSChGCpq2zMjPqj741Z1gKe0wuQ7RpKAWrd1Ui2tG23r6TCigYCE7cb4BEI/KRJkWP0LbfTG8S/9tvuX+xuSgd78qc5nXGqEpMz+FR+b0yC2UcBBup3HO9WZ/3Ut8BjA=", "rVJM6LaFd8PboQCHvwDelQ==")
 	t.Log(string(resp))
 }
+
+// TestProgram_SubInit ...
+func TestProgram_SubInit(t *testing.T) {
+	p := mini.NewMiniProgram(cfg).SubInit()
+	for _, name := range []string{"AppCode", "Cloud", "CustomerService", "DataCube", "Live", "Logistics", "Phone", "Security", "SessionManager", "Subscribe"} {
+		if _, b := p.Sub[name]; !b {
+			t.Error(name, p.Sub)
+		}
+	}
+	if _, b := p.Sub["Security"].(*mini.Security); !b || p.Security() != p.Sub["Security"] {
+		t.Error(p.Sub["Security"])
+	}
+	p = mini.NewMiniProgram(cfg).SubOnlyInit("Security")
+	if len(p.Sub) != 1 {
+		t.Error(p.Sub)
+	}
+}
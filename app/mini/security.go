@@ -0,0 +1,140 @@
+package mini
+
+import (
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+/*SecScene 内容安全检测场景 */
+type SecScene int
+
+/*SecScene types */
+const (
+	SecSceneProfile SecScene = 1 //资料
+	SecSceneComment SecScene = 2 //评论
+	SecSceneForum   SecScene = 3 //论坛
+	SecSceneSocial  SecScene = 4 //社交日志
+)
+
+/*SecMediaType 异步检测的多媒体类型 */
+type SecMediaType int
+
+/*SecMediaType types */
+const (
+	SecMediaTypeAudio SecMediaType = 1 //音频
+	SecMediaTypeImage SecMediaType = 2 //图片
+)
+
+// errCodeRiskyContent 内容含有违法违规内容(version 1)
+const errCodeRiskyContent = 87014
+
+/*SecCheckResult 内容安全检测结果 */
+type SecCheckResult struct {
+	ErrCode int                  `json:"errcode"`
+	ErrMsg  string               `json:"errmsg"`
+	TraceID string               `json:"trace_id"`
+	Result  message.RiskResult   `json:"result"`
+	Detail  []message.RiskDetail `json:"detail"`
+}
+
+/*Risky 是否命中违规内容 */
+func (r *SecCheckResult) Risky() bool {
+	return r.ErrCode == errCodeRiskyContent || r.Result.Suggest == message.RiskSuggestRisky
+}
+
+/*ParseSecCheckResult 解析内容安全接口返回,errcode 87014视为违规结果而非错误 */
+func ParseSecCheckResult(data []byte) (*SecCheckResult, error) {
+	var r SecCheckResult
+	err := parseResult(data, &r)
+	if e, b := err.(*resultError); b && e.ErrCode == errCodeRiskyContent {
+		r.ErrCode, r.ErrMsg = e.ErrCode, e.ErrMsg
+		r.Result.Suggest = message.RiskSuggestRisky
+		return &r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+/*Security 内容安全
+音视频异步检测结果通过wxa_media_check事件推送,可在Server回调中通过core.Message.DecodeEvent
+解析为*message.WxaMediaCheckEvent */
+type Security struct {
+	*Program
+}
+
+func newSecurity(program *Program) interface{} {
+	return &Security{
+		Program: program,
+	}
+}
+
+/*NewSecurity NewSecurity */
+func NewSecurity(config *core.Config) *Security {
+	return newSecurity(NewMiniProgram(config)).(*Security)
+}
+
+/*MsgSecCheck 检查一段文本是否含有违法违规内容
+接口地址:
+POST https://api.weixin.qq.com/wxa/msg_sec_check?access_token=ACCESS_TOKEN
+参数	必填	说明
+content	是	需检测的文本内容，文本字数的上限为2500字
+version	是	接口版本号，2.0版本为固定值2
+scene	是	场景枚举值（1 资料；2 评论；3 论坛；4 社交日志）
+openid	是	用户的openid（用户需在近两小时访问过小程序）
+成功:
+{"errcode":0,"errmsg":"ok","result":{"suggest":"risky","label":20001},"detail":[...],"trace_id":"60ae120f-371d5872-7941a05b"}
+*/
+func (s *Security) MsgSecCheck(openID string, scene SecScene, content string) core.Responder {
+	log.Debug("Security|MsgSecCheck", openID, scene)
+	key := s.accessToken.GetToken().KeyMap()
+	return core.PostJSON(Link(wxaMsgSecCheck), key, util.Map{
+		"content": content,
+		"version": 2,
+		"scene":   scene,
+		"openid":  openID,
+	})
+}
+
+/*ImgSecCheck 校验一张图片是否含有违法违规内容,图片尺寸不超过750px x 1334px
+接口地址:
+POST https://api.weixin.qq.com/wxa/img_sec_check?access_token=ACCESS_TOKEN
+成功:
+{"errcode":0,"errmsg":"ok"}
+违规:
+{"errcode":87014,"errmsg":"risky content"}
+*/
+func (s *Security) ImgSecCheck(filePath string) core.Responder {
+	log.Debug("Security|ImgSecCheck", filePath)
+	key := s.accessToken.GetToken().KeyMap()
+	return core.Upload(Link(wxaImgSecCheck), key, util.Map{
+		"media": filePath,
+	})
+}
+
+/*MediaCheckAsync 异步校验图片/音频是否含有违法违规内容,结果通过wxa_media_check事件推送
+接口地址:
+POST https://api.weixin.qq.com/wxa/media_check_async?access_token=ACCESS_TOKEN
+参数	必填	说明
+media_url	是	要检测的多媒体url
+media_type	是	1:音频;2:图片
+version	是	接口版本号，2.0版本为固定值2
+scene	是	场景枚举值（1 资料；2 评论；3 论坛；4 社交日志）
+openid	是	用户的openid（用户需在近两小时访问过小程序）
+成功:
+{"errcode":0,"errmsg":"ok","trace_id":"967e945cd8a3e458f3c74dcb886068e9"}
+*/
+func (s *Security) MediaCheckAsync(mediaURL string, mediaType SecMediaType, openID string, scene SecScene) core.Responder {
+	log.Debug("Security|MediaCheckAsync", mediaURL, mediaType)
+	key := s.accessToken.GetToken().KeyMap()
+	return core.PostJSON(Link(wxaMediaCheckAsync), key, util.Map{
+		"media_url":  mediaURL,
+		"media_type": mediaType,
+		"version":    2,
+		"scene":      scene,
+		"openid":     openID,
+	})
+}
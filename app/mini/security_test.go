@@ -0,0 +1,49 @@
+package mini_test
+
+import (
+	"testing"
+
+	"github.com/godcong/wego/app/mini"
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+)
+
+// TestParseSecCheckResult ...
+func TestParseSecCheckResult(t *testing.T) {
+	r, err := mini.ParseSecCheckResult([]byte(`{"errcode":0,"errmsg":"ok","result":{"suggest":"risky","label":20001},"detail":[{"strategy":"content_model","errcode":0,"suggest":"risky","label":20006,"prob":90}],"trace_id":"60ae120f-371d5872-7941a05b"}`))
+	if err != nil || !r.Risky() || r.Result.Label != message.RiskLabelPolitics || r.Detail[0].Label != message.RiskLabelCrime {
+		t.Error(r, err)
+	}
+	r, err = mini.ParseSecCheckResult([]byte(`{"errcode":87014,"errmsg":"risky content"}`))
+	if err != nil || !r.Risky() {
+		t.Error(r, err)
+	}
+	if _, err = mini.ParseSecCheckResult([]byte(`{"errcode":40001,"errmsg":"invalid credential"}`)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestMessage_DecodeEvent_MediaCheck ...
+func TestMessage_DecodeEvent_MediaCheck(t *testing.T) {
+	data := `<xml><ToUserName><![CDATA[gh_38cc49f9733b]]></ToUserName><FromUserName><![CDATA[oH1fu0FdHqpToe2T6gBj0WyB8iS1]]></FromUserName><CreateTime>1626959646</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[wxa_media_check]]></Event><appid><![CDATA[wx8f16a5e5c6ca1e0c]]></appid><trace_id><![CDATA[60f96f1d-3845297a-1976a3ae]]></trace_id><version>2</version><detail><strategy><![CDATA[content_model]]></strategy><errcode>0</errcode><suggest><![CDATA[pass]]></suggest><label>100</label><prob>90</prob></detail><errcode>0</errcode><errmsg><![CDATA[ok]]></errmsg><result><suggest><![CDATA[pass]]></suggest><label>100</label></result></xml>`
+	msg, err := core.ParseMessage([]byte(data), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt, err := msg.DecodeEvent()
+	e, b := evt.(*message.WxaMediaCheckEvent)
+	if err != nil || !b || e.Risky() || e.TraceID != "60f96f1d-3845297a-1976a3ae" || e.Result.Label != message.RiskLabelNormal || len(e.Detail) != 1 {
+		t.Errorf("%+v %v", evt, err)
+	}
+
+	data = `{"ToUserName":"gh_38cc49f9733b","FromUserName":"oH1fu0FdHqpToe2T6gBj0WyB8iS1","CreateTime":1626959646,"MsgType":"event","Event":"wxa_media_check","appid":"wx8f16a5e5c6ca1e0c","trace_id":"60f96f1d-3845297a-1976a3ae","version":2,"detail":[{"strategy":"content_model","errcode":0,"suggest":"risky","label":20002,"prob":90}],"errcode":0,"errmsg":"ok","result":{"suggest":"risky","label":20002}}`
+	msg, err = core.ParseMessage([]byte(data), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt, err = msg.DecodeEvent()
+	e, b = evt.(*message.WxaMediaCheckEvent)
+	if err != nil || !b || !e.Risky() || e.Result.Label != message.RiskLabelPorn {
+		t.Errorf("%+v %v", evt, err)
+	}
+}
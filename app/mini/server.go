@@ -2,7 +2,6 @@ package mini
 
 import (
 	"bytes"
-	"encoding/xml"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -11,13 +10,15 @@ import (
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/core/message"
 	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
 )
 
 /*Server Server */
 type Server struct {
-	CryptResponse   bool
+	CryptResponse   bool               //兼容模式下是否加密回复,默认明文回复;安全模式只有密文,回复始终加密
+	Verifier        *core.Verifier     //时间戳及nonce校验,为nil时不校验
+	Deduplicator    *core.Deduplicator //重复消息过滤,为nil时不过滤
 	message         *core.Message
-	mType           string
 	bizMsg          *cipher.BizMsg
 	defaultCallback []core.MessageCallback
 	callback        map[message.MsgType][]core.MessageCallback
@@ -50,68 +51,181 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// Restore the io.ReadCloser to its original state
 	req.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
 
-	if len(bodyBytes) == 0 {
-		w.WriteHeader(http.StatusOK)
-	}
 	query, err := url.ParseQuery(req.URL.RawQuery)
+	//错误返回,并记录log
 	if err != nil {
 		log.Error(err)
-		w.WriteHeader(http.StatusOK)
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
 	encryptType := query.Get("encrypt_type")
 	ts := query.Get("timestamp")
 	nonce := query.Get("nonce")
 	msgSignature := query.Get("msg_signature")
 
+	//服务器地址验证
+	if echoStr := query.Get("echostr"); echoStr != "" {
+		s.serveEcho(w, query)
+		return
+	}
+	if err := s.verify(query); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	//无数据直接返回
+	if len(bodyBytes) == 0 {
+		return
+	}
+	//小程序可配置为json格式推送
+	isJSON := bytes.HasPrefix(bytes.TrimSpace(bodyBytes), []byte("{"))
+
+	//安全模式只包含密文,兼容模式同时包含明文和密文
+	cryptReply := false
 	if encryptType == "aes" {
 		log.Debug(ts, nonce, msgSignature, string(bodyBytes))
-		bodyBytes, err = s.bizMsg.Decrypt(string(bodyBytes), msgSignature, ts, nonce)
-		if err != nil {
+		var compatible bool
+		var decrypted []byte
+		if isJSON {
+			compatible = util.JSONToMap(bodyBytes).Has("MsgType")
+			decrypted, err = s.bizMsg.DecryptJSON(string(bodyBytes), msgSignature, ts, nonce)
+		} else {
+			compatible = util.XMLToMap(bodyBytes).Has("MsgType")
+			decrypted, err = s.bizMsg.Decrypt(string(bodyBytes), msgSignature, ts, nonce)
+		}
+		if err != nil && !compatible {
 			log.Error(err)
-			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if err == nil {
+			bodyBytes = decrypted
+			//安全模式下公众平台只接受密文回复,CryptResponse仅作用于兼容模式
+			cryptReply = !compatible || s.CryptResponse
 		}
 	}
 
-	message := new(core.Message)
 	log.Debug(string(bodyBytes))
-	err = xml.Unmarshal(bodyBytes, message)
+	message, err := core.ParseMessage(bodyBytes, isJSON)
 	if err != nil {
 		log.Error(err)
 		return
 	}
-	result := s.CallbackFunc(message)
-	w.WriteHeader(http.StatusOK)
-
-	rltXML, err = result.ToXML()
+	rltXML, err = s.reply(message, isJSON)
 	if err != nil {
 		log.Error(err)
 		return
 	}
+	if len(rltXML) == 0 {
+		_, _ = w.Write([]byte("success"))
+		return
+	}
 
-	//if encryptType == "aes" {
-	//	tmpStr, err := s.bizMsg.RSAEncrypt(string(rltXML), ts, nonce)
-	//	if err != nil {
-	//		log.Error(err)
-	//		return
-	//	}
-	//	rltXML = []byte(tmpStr)
-	//}
-	if s.mType == "xml" {
-		header := w.Header()
-		if val := header["Content-Type"]; len(val) == 0 {
-			header["Content-Type"] = []string{"application/xml; charset=utf-8"}
-		}
-	} else {
-		header := w.Header()
+	header := w.Header()
+	if isJSON {
 		if val := header["Content-Type"]; len(val) == 0 {
 			header["Content-Type"] = []string{"application/json; charset=utf-8"}
 		}
+	} else if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{"application/xml; charset=utf-8"}
+	}
+
+	if cryptReply {
+		var tmpStr string
+		if isJSON {
+			tmpStr, err = s.bizMsg.EncryptJSON(string(rltXML), ts, nonce)
+		} else {
+			tmpStr, err = s.bizMsg.Encrypt(string(rltXML), ts, nonce)
+		}
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		rltXML = []byte(tmpStr)
 	}
 	log.Debug(string(rltXML))
-	w.Write(rltXML)
+	_, _ = w.Write(rltXML)
 	return
 }
 
+// reply 执行回调并返回回复内容,重复推送的消息直接返回首次处理的回复
+func (s *Server) reply(msg *core.Message, isJSON bool) ([]byte, error) {
+	if s.Deduplicator != nil {
+		if rlt, dup := s.Deduplicator.Check(msg); dup {
+			return rlt, nil
+		}
+	}
+	var rltXML []byte
+	var err error
+	if result := s.CallbackFunc(msg); result != nil {
+		rltXML, err = marshalReply(result, isJSON)
+	}
+	if s.Deduplicator != nil && err == nil {
+		s.Deduplicator.Save(msg, rltXML)
+	}
+	return rltXML, err
+}
+
+/*marshalReply 按推送格式编码回复
+json格式时被动回复(message.Reply)直接编码为json,其他消息类型的ToJSON为客服消息格式,由xml转换
+*/
+func marshalReply(result message.Messager, isJSON bool) ([]byte, error) {
+	if !isJSON {
+		return result.ToXML()
+	}
+	if reply, b := result.(*message.Reply); b {
+		return reply.ToJSON()
+	}
+	rlt, err := result.ToXML()
+	if err != nil {
+		return nil, err
+	}
+	return util.XMLToMap(rlt).ToJSON(), nil
+}
+
+// verify 校验请求签名,时间戳及nonce
+func (s *Server) verify(query url.Values) error {
+	ts := query.Get("timestamp")
+	nonce := query.Get("nonce")
+	if !s.bizMsg.VerifySignature(query.Get("signature"), ts, nonce) {
+		return core.ErrInvalidSignature
+	}
+	if s.Verifier != nil {
+		return s.Verifier.Verify(ts, nonce)
+	}
+	return nil
+}
+
+// serveEcho 处理服务器地址验证,安全模式下echostr需要解密
+func (s *Server) serveEcho(w http.ResponseWriter, query url.Values) {
+	ts := query.Get("timestamp")
+	nonce := query.Get("nonce")
+	echoStr := query.Get("echostr")
+	if msgSignature := query.Get("msg_signature"); msgSignature != "" {
+		echo, err := s.bizMsg.DecryptEchoStr(echoStr, msgSignature, ts, nonce)
+		if err != nil {
+			log.Error(err)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		echoStr = string(echo)
+		if s.Verifier != nil {
+			err = s.Verifier.Verify(ts, nonce)
+		}
+		if err != nil {
+			log.Error(err)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	} else if err := s.verify(query); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(echoStr))
+}
+
 /*CallbackFunc CallbackFunc */
 func (s *Server) CallbackFunc(msg *core.Message) message.Messager {
 	var result message.Messager
@@ -133,7 +247,8 @@ func (s *Server) CallbackFunc(msg *core.Message) message.Messager {
 
 func newServer(token, key, id string) *Server {
 	return &Server{
-		mType:           "xml",
+		Verifier:        core.NewVerifier(id),
+		Deduplicator:    core.NewDeduplicator(id),
 		bizMsg:          cipher.NewBizMsg(token, key, id),
 		message:         nil,
 		defaultCallback: []core.MessageCallback{},
@@ -141,8 +256,11 @@ func newServer(token, key, id string) *Server {
 	}
 }
 
-/*NewServer NewServer */
+/*NewServer NewServer
+消息校验Token读取token配置,兼容旧版本的accessToken配置
+*/
 func NewServer(config *core.Config) *Server {
-	log.Debug(config.Get("accessToken"), config.Get("aes_key"), config.Get("app_id"))
-	return newServer(config.GetString("accessToken"), config.GetString("aes_key"), config.GetString("app_id"))
+	token := config.GetStringD("token", config.GetString("accessToken"))
+	log.Debug(token, config.Get("aes_key"), config.Get("app_id"))
+	return newServer(token, config.GetString("aes_key"), config.GetString("app_id"))
 }
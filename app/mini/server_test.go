@@ -0,0 +1,343 @@
+package mini_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godcong/wego"
+	"github.com/godcong/wego/app/mini"
+	"github.com/godcong/wego/cipher"
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+	"github.com/godcong/wego/util"
+)
+
+var serverConfig = wego.C(util.Map{
+	"app_id":  "wxbafed7010e0f4531",
+	"token":   "godcong",
+	"aes_key": "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu",
+})
+
+var textRequest = `<xml><ToUserName><![CDATA[gh_56870ffd193b]]></ToUserName><FromUserName><![CDATA[oLyBi0hSYhggnD-kOIms0IzZFqrc]]></FromUserName><CreateTime>1524409354</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hello]]></Content><MsgId>6547288321577417974</MsgId></xml>`
+
+// TestServer_ServeHTTP ...
+func TestServer_ServeHTTP(t *testing.T) {
+	biz := cipher.NewBizMsg("godcong", "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu", "wxbafed7010e0f4531")
+	ts, nonce := strconv.FormatInt(time.Now().Unix(), 10), "542437598"
+	body, err := biz.Encrypt(textRequest, ts, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := mini.NewServer(serverConfig)
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		return message.NewText(&msg.Message, "world")
+	}, message.MsgTypeText)
+
+	query := url.Values{
+		"encrypt_type":  {"aes"},
+		"timestamp":     {ts},
+		"nonce":         {nonce},
+		"signature":     {biz.Signature(ts, nonce)},
+		"msg_signature": {util.XMLToMap([]byte(body)).GetString("MsgSignature")},
+	}
+	req := httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	rlt, _ := ioutil.ReadAll(w.Body)
+	reply := util.XMLToMap(rlt)
+	if !reply.Has("Encrypt") {
+		t.Fatal(string(rlt))
+	}
+	dec, err := biz.Decrypt(string(rlt), reply.GetString("MsgSignature"), ts, nonce)
+	if err != nil || !strings.Contains(string(dec), "world") {
+		t.Error(string(dec), err)
+	}
+}
+
+// TestServer_ServeHTTP_Verify ...
+func TestServer_ServeHTTP_Verify(t *testing.T) {
+	biz := cipher.NewBizMsg("godcong", "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu", "wxbafed7010e0f4531")
+	server := mini.NewServer(serverConfig)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	serve := func(method string, query url.Values, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/?"+query.Encode(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	//echostr明文验证
+	w := serve("GET", url.Values{
+		"timestamp": {ts},
+		"nonce":     {"100001"},
+		"signature": {biz.Signature(ts, "100001")},
+		"echostr":   {"echo"},
+	}, "")
+	if w.Code != 200 || w.Body.String() != "echo" {
+		t.Error(w.Code, w.Body.String())
+	}
+
+	//伪造签名
+	w = serve("POST", url.Values{
+		"timestamp": {ts},
+		"nonce":     {"100002"},
+		"signature": {"forged"},
+	}, textRequest)
+	if w.Code != 403 {
+		t.Error(w.Code)
+	}
+
+	//微信重试推送复用timestamp和nonce,默认不拒绝,由去重返回首次的回复
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		return message.NewText(&msg.Message, "retry")
+	}, message.MsgTypeText)
+	query := url.Values{
+		"timestamp": {ts},
+		"nonce":     {"100003"},
+		"signature": {biz.Signature(ts, "100003")},
+	}
+	retry := strings.Replace(textRequest, "6547288321577417974", "6547288321577417990", 1)
+	first := serve("POST", query, retry)
+	if w = serve("POST", query, retry); w.Code != 200 || first.Code != 200 ||
+		!strings.Contains(w.Body.String(), "retry") || w.Body.String() != first.Body.String() {
+		t.Error(w.Code, w.Body.String(), first.Body.String())
+	}
+
+	//开启nonce校验后拒绝重放
+	server.Verifier.Nonce = true
+	query = url.Values{
+		"timestamp": {ts},
+		"nonce":     {"100005"},
+		"signature": {biz.Signature(ts, "100005")},
+	}
+	if w = serve("POST", query, textRequest); w.Code != 200 {
+		t.Error(w.Code)
+	}
+	if w = serve("POST", query, textRequest); w.Code != 403 {
+		t.Error(w.Code)
+	}
+
+	//时间戳过期
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	w = serve("POST", url.Values{
+		"timestamp": {old},
+		"nonce":     {"100004"},
+		"signature": {biz.Signature(old, "100004")},
+	}, textRequest)
+	if w.Code != 403 {
+		t.Error(w.Code)
+	}
+}
+
+// TestServer_ServeHTTP_Dedupe ...
+func TestServer_ServeHTTP_Dedupe(t *testing.T) {
+	biz := cipher.NewBizMsg("godcong", "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu", "wxbafed7010e0f4531")
+	server := mini.NewServer(serverConfig)
+	count := 0
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		count++
+		return message.NewText(&msg.Message, "once")
+	})
+
+	body := strings.Replace(textRequest, "6547288321577417974", "6547288321577417975", 1)
+	var replies []string
+	ts, nonce := strconv.FormatInt(time.Now().Unix(), 10), "200001"
+	for i := 0; i < 3; i++ {
+		query := url.Values{
+			"timestamp": {ts},
+			"nonce":     {nonce},
+			"signature": {biz.Signature(ts, nonce)},
+		}
+		req := httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		replies = append(replies, w.Body.String())
+	}
+	if count != 1 {
+		t.Error(count)
+	}
+	if !strings.Contains(replies[0], "once") || replies[0] != replies[1] || replies[1] != replies[2] {
+		t.Error(replies)
+	}
+}
+
+// TestServer_ServeHTTP_JSON ...
+func TestServer_ServeHTTP_JSON(t *testing.T) {
+	biz := cipher.NewBizMsg("godcong", "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu", "wxbafed7010e0f4531")
+	server := mini.NewServer(serverConfig)
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		if msg.Content.Value != "json" || msg.MsgID != 6547288321577417976 {
+			t.Errorf("%+v", msg)
+		}
+		return message.NewTransferReply(&msg.Message, "")
+	}, message.MsgTypeText)
+
+	body := `{"ToUserName":"gh_56870ffd193b","FromUserName":"oLyBi0hSYhggnD-kOIms0IzZFqrc","CreateTime":1524409354,"MsgType":"text","Content":"json","MsgId":6547288321577417976}`
+	ts, nonce := strconv.FormatInt(time.Now().Unix(), 10), "300001"
+	query := url.Values{
+		"timestamp": {ts},
+		"nonce":     {nonce},
+		"signature": {biz.Signature(ts, nonce)},
+	}
+	req := httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	reply := util.JSONToMap(w.Body.Bytes())
+	if reply.GetString("MsgType") != "transfer_customer_service" || reply.GetString("ToUserName") != "oLyBi0hSYhggnD-kOIms0IzZFqrc" ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Error(w.Body.String())
+	}
+
+	//安全模式
+	body = strings.Replace(body, "6547288321577417976", "6547288321577417977", 1)
+	server = mini.NewServer(serverConfig)
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		return message.NewTextReply(&msg.Message, msg.Content.Value)
+	}, message.MsgTypeText)
+	nonce = "300002"
+	encrypted, err := biz.EncryptJSON(body, ts, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query = url.Values{
+		"encrypt_type":  {"aes"},
+		"timestamp":     {ts},
+		"nonce":         {nonce},
+		"signature":     {biz.Signature(ts, nonce)},
+		"msg_signature": {util.JSONToMap([]byte(encrypted)).GetString("MsgSignature")},
+	}
+	req = httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(encrypted))
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	reply = util.JSONToMap(w.Body.Bytes())
+	dec, err := biz.DecryptJSON(w.Body.String(), reply.GetString("MsgSignature"), ts, nonce)
+	if err != nil || util.JSONToMap(dec).GetString("Content") != "json" {
+		t.Error(w.Body.String(), string(dec), err)
+	}
+}
+
+// TestServer_ServeHTTP_Compatible ...
+func TestServer_ServeHTTP_Compatible(t *testing.T) {
+	biz := cipher.NewBizMsg("godcong", "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu", "wxbafed7010e0f4531")
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	serve := func(server *mini.Server, msgID, nonce string) []byte {
+		plain := strings.Replace(textRequest, "6547288321577417974", msgID, 1)
+		encrypted, err := biz.Encrypt(plain, ts, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc := util.XMLToMap([]byte(encrypted))
+		//兼容模式同时包含明文和密文
+		body := strings.TrimSuffix(plain, "</xml>") + "<Encrypt><![CDATA[" + enc.GetString("Encrypt") + "]]></Encrypt></xml>"
+		query := url.Values{
+			"encrypt_type":  {"aes"},
+			"timestamp":     {ts},
+			"nonce":         {nonce},
+			"signature":     {biz.Signature(ts, nonce)},
+			"msg_signature": {enc.GetString("MsgSignature")},
+		}
+		server.RegisterCallback(func(msg *core.Message) message.Messager {
+			return message.NewText(&msg.Message, "world")
+		}, message.MsgTypeText)
+		req := httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Body.Bytes()
+	}
+
+	//默认明文回复
+	rlt := serve(mini.NewServer(serverConfig), "6547288321577417980", "400001")
+	if reply := util.XMLToMap(rlt); reply.Has("Encrypt") || !strings.Contains(string(rlt), "world") {
+		t.Error(string(rlt))
+	}
+
+	server := mini.NewServer(serverConfig)
+	server.CryptResponse = true
+	rlt = serve(server, "6547288321577417981", "400002")
+	if reply := util.XMLToMap(rlt); !reply.Has("Encrypt") {
+		t.Error(string(rlt))
+	}
+
+	//兼容旧版本accessToken配置
+	server = mini.NewServer(wego.C(util.Map{
+		"app_id":      "wxbafed7010e0f4531",
+		"accessToken": "godcong",
+		"aes_key":     "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu",
+	}))
+	server.CryptResponse = true
+	rlt = serve(server, "6547288321577417982", "400003")
+	if reply := util.XMLToMap(rlt); !reply.Has("Encrypt") {
+		t.Error(string(rlt))
+	}
+}
+
+// TestServer_ServeHTTP_JSONReply ...
+func TestServer_ServeHTTP_JSONReply(t *testing.T) {
+	biz := cipher.NewBizMsg("godcong", "TNwHN28RXXoyVxkMCUEqKuCL08eBpCKgWZTkWNVnGLu", "wxbafed7010e0f4531")
+	server := mini.NewServer(serverConfig)
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		if msg.Content.Value == "image" {
+			return message.NewImageReply(&msg.Message, "media_id")
+		}
+		return message.NewNewsReply(&msg.Message,
+			&message.ReplyArticle{Title: "first", URL: "https://github.com/godcong/wego"},
+			&message.ReplyArticle{Title: "second", PicURL: "https://github.com/godcong/wego.png"},
+		)
+	}, message.MsgTypeText)
+
+	serve := func(msgID, content string) []byte {
+		body := `{"ToUserName":"gh_56870ffd193b","FromUserName":"oLyBi0hSYhggnD-kOIms0IzZFqrc","CreateTime":1524409354,"MsgType":"text","Content":"` +
+			content + `","MsgId":` + msgID + `}`
+		ts, nonce := strconv.FormatInt(time.Now().Unix(), 10), "500001"
+		query := url.Values{
+			"timestamp": {ts},
+			"nonce":     {nonce},
+			"signature": {biz.Signature(ts, nonce)},
+		}
+		req := httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Body.Bytes()
+	}
+
+	var news struct {
+		ToUserName   string
+		CreateTime   int64
+		MsgType      string
+		ArticleCount int
+		Articles     struct {
+			Item []struct {
+				Title  string
+				PicURL string `json:"PicUrl"`
+			} `json:"item"`
+		}
+	}
+	rlt := serve("6547288321577417991", "news")
+	if err := json.Unmarshal(rlt, &news); err != nil {
+		t.Fatal(err, string(rlt))
+	}
+	if news.MsgType != "news" || news.CreateTime == 0 || news.ArticleCount != 2 || len(news.Articles.Item) != 2 ||
+		news.Articles.Item[1].PicURL != "https://github.com/godcong/wego.png" {
+		t.Error(string(rlt))
+	}
+
+	var image struct {
+		MsgType string
+		Image   struct {
+			MediaID string `json:"MediaId"`
+		}
+	}
+	rlt = serve("6547288321577417992", "image")
+	if err := json.Unmarshal(rlt, &image); err != nil || image.MsgType != "image" || image.Image.MediaID != "media_id" {
+		t.Error(string(rlt), err)
+	}
+}
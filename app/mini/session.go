@@ -0,0 +1,343 @@
+package mini
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/godcong/wego/cache"
+	"github.com/godcong/wego/cipher"
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+// DefaultSessionTTL 默认自定义登录态有效期
+const DefaultSessionTTL = 24 * time.Hour
+
+// DefaultWatermarkExpire 默认加密数据watermark有效期
+const DefaultWatermarkExpire = 10 * time.Minute
+
+/*Session 自定义登录态,session_key仅保存在服务端 */
+type Session struct {
+	Token      string `json:"token"`
+	OpenID     string `json:"openid"`
+	UnionID    string `json:"unionid,omitempty"`
+	SessionKey string `json:"session_key"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+/*Watermark 敏感数据水印 */
+type Watermark struct {
+	AppID     string `json:"appid"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+/*UserInfo 用户信息(wx.getUserInfo) */
+type UserInfo struct {
+	OpenID    string    `json:"openId"`
+	NickName  string    `json:"nickName"`
+	Gender    int       `json:"gender"`
+	City      string    `json:"city"`
+	Province  string    `json:"province"`
+	Country   string    `json:"country"`
+	AvatarURL string    `json:"avatarUrl"`
+	UnionID   string    `json:"unionId"`
+	Language  string    `json:"language"`
+	Watermark Watermark `json:"watermark"`
+}
+
+/*PhoneNumber 用户手机号(getPhoneNumber) */
+type PhoneNumber struct {
+	PhoneNumber     string      `json:"phoneNumber"`
+	PurePhoneNumber string      `json:"purePhoneNumber"`
+	CountryCode     json.Number `json:"countryCode"` //解密数据为字符串,code换取时为数字
+	Watermark       Watermark   `json:"watermark"`
+}
+
+/*ShareInfo 转发信息(wx.getShareInfo) */
+type ShareInfo struct {
+	OpenGID   string    `json:"openGId"`
+	Watermark Watermark `json:"watermark"`
+}
+
+/*RunStep 单日步数 */
+type RunStep struct {
+	Timestamp int64 `json:"timestamp"`
+	Step      int   `json:"step"`
+}
+
+/*RunData 微信运动步数(wx.getWeRunData) */
+type RunData struct {
+	StepInfoList []*RunStep `json:"stepInfoList"`
+	Watermark    Watermark  `json:"watermark"`
+}
+
+// sessionMutex 保护同一openid的token轮换
+var sessionMutex sync.Mutex
+
+/*SessionManager 登录态管理
+使用code换取session_key后,以自定义token保存于缓存,小程序端只持有token,
+同一用户重新登录时旧token失效
+token轮换通过进程内的锁保证原子,仅适用于单进程部署;多进程共享Cache时并发登录可能残留旧token直至过期 */
+type SessionManager struct {
+	*Program
+	Cache           cache.Cache //登录态存储,为nil时使用默认缓存
+	TTL             time.Duration
+	WatermarkExpire time.Duration
+	dc              *cipher.DataCrypt
+}
+
+func newSessionManager(program *Program) interface{} {
+	return &SessionManager{
+		Program:         program,
+		TTL:             DefaultSessionTTL,
+		WatermarkExpire: DefaultWatermarkExpire,
+		dc:              cipher.NewDataCrypt(program.GetString("app_id")),
+	}
+}
+
+/*NewSessionManager NewSessionManager */
+func NewSessionManager(config *core.Config) *SessionManager {
+	return newSessionManager(NewMiniProgram(config)).(*SessionManager)
+}
+
+/*Login 使用wx.login获取的code换取session_key并签发token */
+func (m *SessionManager) Login(code string) (*Session, error) {
+	resp, err := m.Auth().Session(code).Result()
+	if err != nil {
+		return nil, err
+	}
+	if c, b := resp.GetInt64("errcode"); b && c != 0 {
+		return nil, errors.New(resp.GetString("errmsg"))
+	}
+	return m.Store(resp.GetString("openid"), resp.GetString("unionid"), resp.GetString("session_key"))
+}
+
+/*Store 保存session_key并签发新token,同一openid的旧token失效 */
+func (m *SessionManager) Store(openID, unionID, sessionKey string) (*Session, error) {
+	if openID == "" || sessionKey == "" {
+		return nil, ErrSessionKeyEmpty
+	}
+	token, err := newSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{
+		Token:      token,
+		OpenID:     openID,
+		UnionID:    unionID,
+		SessionKey: sessionKey,
+		CreatedAt:  time.Now().Unix(),
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	c := m.cache()
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	if old, b := c.Get(m.openIDKey(openID)).(string); b && old != "" {
+		c.Delete(m.tokenKey(old))
+	}
+	m.set(c, s, data)
+	return s, nil
+}
+
+/*Validate 校验token,返回对应的登录态 */
+func (m *SessionManager) Validate(token string) (*Session, error) {
+	if token == "" {
+		return nil, ErrSessionNotFound
+	}
+	v, b := m.cache().Get(m.tokenKey(token)).(string)
+	if !b || v == "" {
+		return nil, ErrSessionNotFound
+	}
+	var s Session
+	if err := json.Unmarshal([]byte(v), &s); err != nil {
+		log.Error(err)
+		return nil, ErrSessionNotFound
+	}
+	return &s, nil
+}
+
+/*Logout 注销token */
+func (m *SessionManager) Logout(token string) {
+	s, err := m.Validate(token)
+	if err != nil {
+		return
+	}
+	c := m.cache()
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	c.Delete(m.tokenKey(token))
+	if cur, b := c.Get(m.openIDKey(s.OpenID)).(string); b && cur == token {
+		c.Delete(m.openIDKey(s.OpenID))
+	}
+}
+
+/*Check 向微信服务器校验token对应的session_key,失效时注销token */
+func (m *SessionManager) Check(token string) error {
+	s, err := m.Validate(token)
+	if err != nil {
+		return err
+	}
+	resp, err := m.Auth().CheckSessionKey(s.OpenID, s.SessionKey).Result()
+	if err != nil {
+		return err
+	}
+	if c, b := resp.GetInt64("errcode"); b && c != 0 {
+		log.Error("SessionManager|Check", resp.GetString("errmsg"))
+		m.Logout(token)
+		return ErrSessionKeyInvalid
+	}
+	return nil
+}
+
+/*Reset 重置token对应的session_key,token保持不变 */
+func (m *SessionManager) Reset(token string) (*Session, error) {
+	s, err := m.Validate(token)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.Auth().ResetUserSessionKey(s.OpenID, s.SessionKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if c, b := resp.GetInt64("errcode"); b && c != 0 {
+		return nil, errors.New(resp.GetString("errmsg"))
+	}
+	if key := resp.GetString("session_key"); key != "" {
+		s.SessionKey = key
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	c := m.cache()
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	//重置期间token已被注销或轮换时不再写回
+	if cur, b := c.Get(m.openIDKey(s.OpenID)).(string); !b || cur != token {
+		return nil, ErrSessionNotFound
+	}
+	m.set(c, s, data)
+	return s, nil
+}
+
+/*Decrypt 使用token对应的session_key解密encryptedData并校验watermark */
+func (m *SessionManager) Decrypt(token, encryptedData, iv string) ([]byte, error) {
+	s, err := m.Validate(token)
+	if err != nil {
+		return nil, err
+	}
+	data, err := m.dc.Decrypt(encryptedData, iv, s.SessionKey)
+	if err != nil || !json.Valid(data) {
+		log.Error(err)
+		return nil, ErrDecryptData
+	}
+	var v struct {
+		Watermark Watermark `json:"watermark"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, ErrDecryptData
+	}
+	if err := m.CheckWatermark(&v.Watermark); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+/*CheckWatermark 校验watermark的appid及时间戳 */
+func (m *SessionManager) CheckWatermark(w *Watermark) error {
+	if w.AppID != m.GetString("app_id") {
+		return ErrWatermarkAppID
+	}
+	if m.WatermarkExpire > 0 {
+		if time.Since(time.Unix(w.Timestamp, 0)) > m.WatermarkExpire {
+			return ErrWatermarkExpired
+		}
+	}
+	return nil
+}
+
+/*DecryptMap 解密为util.Map */
+func (m *SessionManager) DecryptMap(token, encryptedData, iv string) (util.Map, error) {
+	data, err := m.Decrypt(token, encryptedData, iv)
+	if err != nil {
+		return nil, err
+	}
+	return util.JSONToMap(data), nil
+}
+
+/*UserInfo 解密用户信息 */
+func (m *SessionManager) UserInfo(token, encryptedData, iv string) (*UserInfo, error) {
+	var v UserInfo
+	return &v, m.decryptTo(token, encryptedData, iv, &v)
+}
+
+/*PhoneNumber 解密用户手机号 */
+func (m *SessionManager) PhoneNumber(token, encryptedData, iv string) (*PhoneNumber, error) {
+	var v PhoneNumber
+	return &v, m.decryptTo(token, encryptedData, iv, &v)
+}
+
+/*ShareInfo 解密转发信息 */
+func (m *SessionManager) ShareInfo(token, encryptedData, iv string) (*ShareInfo, error) {
+	var v ShareInfo
+	return &v, m.decryptTo(token, encryptedData, iv, &v)
+}
+
+/*RunData 解密微信运动步数 */
+func (m *SessionManager) RunData(token, encryptedData, iv string) (*RunData, error) {
+	var v RunData
+	return &v, m.decryptTo(token, encryptedData, iv, &v)
+}
+
+func (m *SessionManager) decryptTo(token, encryptedData, iv string, v interface{}) error {
+	data, err := m.Decrypt(token, encryptedData, iv)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+//set 写入登录态及openid到token的索引,调用方需持有sessionMutex
+func (m *SessionManager) set(c cache.Cache, s *Session, data []byte) {
+	t := time.Now().Add(m.ttl())
+	c.SetWithTTL(m.tokenKey(s.Token), string(data), &t)
+	c.SetWithTTL(m.openIDKey(s.OpenID), s.Token, &t)
+}
+
+func (m *SessionManager) cache() cache.Cache {
+	if m.Cache != nil {
+		return m.Cache
+	}
+	return cache.DefaultCache()
+}
+
+func (m *SessionManager) ttl() time.Duration {
+	if m.TTL <= 0 {
+		return DefaultSessionTTL
+	}
+	return m.TTL
+}
+
+func (m *SessionManager) tokenKey(token string) string {
+	return "godcong.wego.mini.session." + m.GetString("app_id") + ".token." + token
+}
+
+func (m *SessionManager) openIDKey(openID string) string {
+	return "godcong.wego.mini.session." + m.GetString("app_id") + ".openid." + openID
+}
+
+func newSessionToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
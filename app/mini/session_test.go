@@ -0,0 +1,141 @@
+package mini_test
+
+import (
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godcong/wego/app/mini"
+	"github.com/godcong/wego/cache"
+	"github.com/godcong/wego/cipher"
+)
+
+func encryptData(t *testing.T, data, key, iv []byte) string {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = cipher.PKCS7Padding(data, block.BlockSize())
+	gocipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+	return string(cipher.Base64Encode(data))
+}
+
+// TestSessionManager_Store ...
+func TestSessionManager_Store(t *testing.T) {
+	sm := mini.NewSessionManager(cfg)
+	s1, err := sm.Store("oE_gl0Yr54fUjBhU5nBlP4hS2efo", "", "UaPsfKqS9eJYxi1PCYYxuA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := sm.Validate(s1.Token); err != nil || s.SessionKey != "UaPsfKqS9eJYxi1PCYYxuA==" {
+		t.Error(s, err)
+	}
+	s2, err := sm.Store("oE_gl0Yr54fUjBhU5nBlP4hS2efo", "", "tiihtNczf5v6AKRyjwEUhQ==")
+	if err != nil || s2.Token == s1.Token {
+		t.Fatal(s2, err)
+	}
+	if _, err := sm.Validate(s1.Token); err != mini.ErrSessionNotFound {
+		t.Error(err)
+	}
+	sm.Logout(s2.Token)
+	if _, err := sm.Validate(s2.Token); err != mini.ErrSessionNotFound {
+		t.Error(err)
+	}
+	if _, err := sm.Store("", "", ""); err != mini.ErrSessionKeyEmpty {
+		t.Error(err)
+	}
+}
+
+// TestSessionManager_Cache ...
+func TestSessionManager_Cache(t *testing.T) {
+	sm := mini.NewSessionManager(cfg)
+	sm.Cache = cache.NewMapCache()
+
+	var wg sync.WaitGroup
+	tokens := make(chan string, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := sm.Store("oE_gl0Yr54fUjBhU5nBlP4hS2eff", "", fmt.Sprintf("session_key_%d", i))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			tokens <- s.Token
+		}(i)
+	}
+	wg.Wait()
+	close(tokens)
+
+	//并发登录后只保留一个有效token
+	valid := 0
+	for token := range tokens {
+		if _, err := sm.Validate(token); err == nil {
+			valid++
+		}
+	}
+	if valid != 1 {
+		t.Error(valid)
+	}
+
+	//登录态只写入注入的缓存
+	s, err := sm.Store("oE_gl0Yr54fUjBhU5nBlP4hS2efg", "", "UaPsfKqS9eJYxi1PCYYxuA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mini.NewSessionManager(cfg).Validate(s.Token); err != mini.ErrSessionNotFound {
+		t.Error(err)
+	}
+}
+
+// TestSessionManager_PhoneNumber ...
+func TestSessionManager_PhoneNumber(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	iv := []byte("fedcba9876543210")
+	sm := mini.NewSessionManager(cfg)
+	s, err := sm.Store("oE_gl0Yr54fUjBhU5nBlP4hS2efo", "", string(cipher.Base64Encode(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ivs := string(cipher.Base64Encode(iv))
+
+	data := fmt.Sprintf(`{"phoneNumber":"13580006666","purePhoneNumber":"13580006666","countryCode":"86","watermark":{"appid":"%s","timestamp":%d}}`, cfg.GetString("app_id"), time.Now().Unix())
+	phone, err := sm.PhoneNumber(s.Token, encryptData(t, []byte(data), key, iv), ivs)
+	if err != nil || phone.PurePhoneNumber != "13580006666" {
+		t.Error(phone, err)
+	}
+
+	data = fmt.Sprintf(`{"openGId":"OPENGID","watermark":{"appid":"wx0000000000000000","timestamp":%d}}`, time.Now().Unix())
+	if _, err := sm.ShareInfo(s.Token, encryptData(t, []byte(data), key, iv), ivs); err != mini.ErrWatermarkAppID {
+		t.Error(err)
+	}
+
+	data = fmt.Sprintf(`{"stepInfoList":[{"timestamp":1445866601,"step":100}],"watermark":{"appid":"%s","timestamp":%d}}`, cfg.GetString("app_id"), time.Now().Add(-time.Hour).Unix())
+	if _, err := sm.RunData(s.Token, encryptData(t, []byte(data), key, iv), ivs); err != mini.ErrWatermarkExpired {
+		t.Error(err)
+	}
+	sm.WatermarkExpire = 0
+	run, err := sm.RunData(s.Token, encryptData(t, []byte(data), key, iv), ivs)
+	if err != nil || len(run.StepInfoList) != 1 || run.StepInfoList[0].Step != 100 {
+		t.Error(run, err)
+	}
+}
+
+// TestSessionSignature ...
+func TestSessionSignature(t *testing.T) {
+	sig := mini.SessionSignature("UaPsfKqS9eJYxi1PCYYxuA==")
+	if sig != "62705aec525d7247e104591ef2ad8bf32cee533b8688745f60fa213ae74e1fdc" {
+		t.Error(sig)
+	}
+}
+
+// TestAuth_CheckSession ...
+func TestAuth_CheckSession(t *testing.T) {
+	if err := mini.NewAuth(cfg).CheckSession("UaPsfKqS9eJYxi1PCYYxuA==").Error(); err != mini.ErrDeprecated {
+		t.Error(err)
+	}
+}
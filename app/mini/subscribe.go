@@ -0,0 +1,168 @@
+package mini
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+/*MiniProgramState 跳转小程序类型 */
+type MiniProgramState string
+
+/*miniprogram_state types */
+const (
+	MiniProgramStateDeveloper MiniProgramState = "developer" //开发版
+	MiniProgramStateTrial     MiniProgramState = "trial"     //体验版
+	MiniProgramStateFormal    MiniProgramState = "formal"    //正式版
+)
+
+/*SubscribeMessage 订阅消息 */
+type SubscribeMessage struct {
+	ToUser           string               `json:"touser"`
+	TemplateID       string               `json:"template_id"`
+	Page             string               `json:"page,omitempty"`
+	Data             message.TemplateData `json:"data"`
+	MiniProgramState MiniProgramState     `json:"miniprogram_state,omitempty"`
+	Lang             string               `json:"lang,omitempty"` //zh_CN(默认),en_US,zh_HK,zh_TW
+}
+
+/*NewSubscribeMessage 创建订阅消息,data的key为模板内容的关键词,如thing1 */
+func NewSubscribeMessage(toUser, templateID string, data map[string]string) *SubscribeMessage {
+	td := message.TemplateData{}
+	for k, v := range data {
+		td[k] = &message.ValueColor{Value: v}
+	}
+	return &SubscribeMessage{
+		ToUser:     toUser,
+		TemplateID: templateID,
+		Data:       td,
+	}
+}
+
+/*Validate 校验必填字段 */
+func (m *SubscribeMessage) Validate() error {
+	if m.ToUser == "" || m.TemplateID == "" || len(m.Data) == 0 {
+		return ErrSubscribeMessageRequired
+	}
+	switch m.MiniProgramState {
+	case "", MiniProgramStateDeveloper, MiniProgramStateTrial, MiniProgramStateFormal:
+	default:
+		return ErrMiniProgramState
+	}
+	return nil
+}
+
+/*Subscribe 订阅消息
+用户操作订阅消息弹窗及管理订阅消息的事件推送,可在Server回调中通过core.Message.DecodeEvent
+解析为*message.SubscribeMsgPopupEvent,*message.SubscribeMsgChangeEvent,*message.SubscribeMsgSentEvent */
+type Subscribe struct {
+	*Program
+}
+
+func newSubscribe(program *Program) interface{} {
+	return &Subscribe{
+		Program: program,
+	}
+}
+
+/*NewSubscribe NewSubscribe */
+func NewSubscribe(config *core.Config) *Subscribe {
+	return newSubscribe(NewMiniProgram(config)).(*Subscribe)
+}
+
+/*GetCategory 获取小程序账号的类目
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/newtmpl/getcategory?access_token=ACCESS_TOKEN
+*/
+func (s *Subscribe) GetCategory() core.Responder {
+	key := s.accessToken.GetToken().KeyMap()
+	return core.Get(Link(newtmplGetCategory), key)
+}
+
+/*GetPubTemplateTitles 获取帐号所属类目下的公共模板标题
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/newtmpl/getpubtemplatetitles?access_token=ACCESS_TOKEN
+参数	必填	说明
+ids	是	类目 id，多个用逗号隔开
+start	是	用于分页，表示从 start 开始。从 0 开始计数。
+limit	是	用于分页，表示拉取 limit 条记录。最大为 30。
+*/
+func (s *Subscribe) GetPubTemplateTitles(ids []string, start, limit int) core.Responder {
+	key := s.accessToken.GetToken().KeyMap()
+	key.Set("ids", strings.Join(ids, ","))
+	key.Set("start", strconv.Itoa(start))
+	key.Set("limit", strconv.Itoa(limit))
+	return core.Get(Link(newtmplGetPubTemplateTitles), key)
+}
+
+/*GetPubTemplateKeywords 获取模板标题下的关键词列表
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/newtmpl/getpubtemplatekeywords?access_token=ACCESS_TOKEN
+参数	必填	说明
+tid	是	模板标题 id，可通过接口获取
+*/
+func (s *Subscribe) GetPubTemplateKeywords(tid string) core.Responder {
+	key := s.accessToken.GetToken().KeyMap()
+	key.Set("tid", tid)
+	return core.Get(Link(newtmplGetPubTemplateKeywords), key)
+}
+
+/*AddTemplate 组合模板并添加至帐号下的个人模板库
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/newtmpl/addtemplate?access_token=ACCESS_TOKEN
+参数	必填	说明
+tid	是	模板标题 id
+kidList	是	开发者自行组合好的模板关键词列表，关键词顺序可以自由搭配（例如 [3,5,4] 或 [4,5,3]），最多支持5个，最少2个关键词组合
+sceneDesc	否	服务场景描述，15个字以内
+成功:
+{"errmsg":"ok","errcode":0,"priTmplId":"9Aw5ZV1j9xdWTFEkqCpZ7mIBbSC34khK55OtzUPl0rU"}
+*/
+func (s *Subscribe) AddTemplate(tid string, kidList []int, sceneDesc string) core.Responder {
+	if len(kidList) < 2 || len(kidList) > 5 {
+		return core.Err(nil, ErrSubscribeKidList)
+	}
+	key := s.accessToken.GetToken().KeyMap()
+	p := util.Map{
+		"tid":     tid,
+		"kidList": kidList,
+	}
+	if sceneDesc != "" {
+		p.Set("sceneDesc", sceneDesc)
+	}
+	return core.PostJSON(Link(newtmplAddTemplate), key, p)
+}
+
+/*DeleteTemplate 删除帐号下的个人模板
+接口地址:
+POST https://api.weixin.qq.com/wxaapi/newtmpl/deltemplate?access_token=ACCESS_TOKEN
+*/
+func (s *Subscribe) DeleteTemplate(priTmplID string) core.Responder {
+	key := s.accessToken.GetToken().KeyMap()
+	return core.PostJSON(Link(newtmplDelTemplate), key, util.Map{"priTmplId": priTmplID})
+}
+
+/*GetTemplateList 获取当前帐号下的个人模板列表
+接口地址:
+GET https://api.weixin.qq.com/wxaapi/newtmpl/gettemplate?access_token=ACCESS_TOKEN
+*/
+func (s *Subscribe) GetTemplateList() core.Responder {
+	key := s.accessToken.GetToken().KeyMap()
+	return core.Get(Link(newtmplGetTemplate), key)
+}
+
+/*Send 发送订阅消息
+接口地址:
+POST https://api.weixin.qq.com/cgi-bin/message/subscribe/send?access_token=ACCESS_TOKEN
+*/
+func (s *Subscribe) Send(msg *SubscribeMessage) core.Responder {
+	log.Debug("Subscribe|Send", msg)
+	if err := msg.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	key := s.accessToken.GetToken().KeyMap()
+	return core.PostJSON(Link(subscribeSend), key, msg)
+}
@@ -0,0 +1,50 @@
+package mini_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/godcong/wego/app/mini"
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+)
+
+// TestSubscribeMessage_Validate ...
+func TestSubscribeMessage_Validate(t *testing.T) {
+	msg := mini.NewSubscribeMessage("oE_gl0Yr54fUjBhU5nBlP4hS2efo", "VRR0UEO9VJOLs0MHlU0OilqX6MVFDwH3_3gz3Oc0NIc", map[string]string{
+		"thing1": "339208499",
+	})
+	if err := msg.Validate(); err != nil {
+		t.Error(err)
+	}
+	msg.MiniProgramState = "beta"
+	if err := msg.Validate(); err != mini.ErrMiniProgramState {
+		t.Error(err)
+	}
+	if err := mini.NewSubscribeMessage("oE_gl0Yr54fUjBhU5nBlP4hS2efo", "", nil).Validate(); err != mini.ErrSubscribeMessageRequired {
+		t.Error(err)
+	}
+}
+
+// TestMessage_DecodeEvent ...
+func TestMessage_DecodeEvent(t *testing.T) {
+	change := `<xml><ToUserName><![CDATA[gh_123456789abc]]></ToUserName><FromUserName><![CDATA[o7esq5OI1Uej6Xixw1lA2H7XDVbc]]></FromUserName><CreateTime>1610968440</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[subscribe_msg_change_event]]></Event><SubscribeMsgChangeEvent><List><TemplateId><![CDATA[BEwX0BOT3MqK3Uc5oTU3CGBqzjpndk2jzXgC0BaDSJs]]></TemplateId><SubscribeStatusString><![CDATA[reject]]></SubscribeStatusString></List></SubscribeMsgChangeEvent></xml>`
+	msg, err := core.ParseMessage([]byte(change), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt, err := msg.DecodeEvent()
+	if c, b := evt.(*message.SubscribeMsgChangeEvent); err != nil || !b || len(c.List) != 1 || c.List[0].SubscribeStatusString != "reject" {
+		t.Errorf("%+v %v", evt, err)
+	}
+
+	popup := `{"ToUserName":"gh_123456789abc","FromUserName":"o7esq5OI1Uej6Xixw1lA2H7XDVbc","CreateTime":1620973045,"MsgType":"event","Event":"subscribe_msg_popup_event","List":[{"TemplateId":"hD-ixGOhYmUfjOnI8MCzQMPshzGVeux_2vBgDhmBmmk","SubscribeStatusString":"accept","PopupScene":"0"}]}`
+	msg, err = core.ParseMessage([]byte(popup), true)
+	if err != nil || !strings.EqualFold(string(msg.Event.Event.Value), "subscribe_msg_popup_event") {
+		t.Fatal(msg, err)
+	}
+	evt, err = msg.DecodeEvent()
+	if p, b := evt.(*message.SubscribeMsgPopupEvent); err != nil || !b || p.List[0].SubscribeStatusString != "accept" {
+		t.Errorf("%+v %v", evt, err)
+	}
+}
@@ -5,7 +5,8 @@ import (
 	"github.com/godcong/wego/util"
 )
 
-/*Template Template */
+/*Template Template
+Deprecated: 小程序模板消息接口已下线,请使用Subscribe订阅消息 */
 type Template struct {
 	*Program
 }
@@ -0,0 +1,181 @@
+package mini
+
+import (
+	"github.com/godcong/wego/core"
+)
+
+/*EnvVersion 打开的小程序版本 */
+type EnvVersion string
+
+/*EnvVersion types */
+const (
+	EnvVersionRelease EnvVersion = "release" //正式版
+	EnvVersionTrial   EnvVersion = "trial"   //体验版
+	EnvVersionDevelop EnvVersion = "develop" //开发版
+)
+
+/*ExpireType 失效类型 */
+type ExpireType int
+
+/*ExpireType types */
+const (
+	ExpireTypeTime     ExpireType = 0 //指定失效时间
+	ExpireTypeInterval ExpireType = 1 //指定失效间隔天数
+)
+
+// MaxExpireInterval 最长有效天数
+const MaxExpireInterval = 30
+
+/*JumpWxa 跳转到的目标小程序信息 */
+type JumpWxa struct {
+	Path       string     `json:"path"`
+	Query      string     `json:"query"`
+	EnvVersion EnvVersion `json:"env_version,omitempty"`
+}
+
+/*Expire 到期失效设置 */
+type Expire struct {
+	IsExpire       bool       `json:"is_expire"`
+	ExpireType     ExpireType `json:"expire_type"`
+	ExpireTime     int64      `json:"expire_time,omitempty"`
+	ExpireInterval int        `json:"expire_interval,omitempty"`
+}
+
+/*Validate 校验失效设置 */
+func (e *Expire) Validate() error {
+	if !e.IsExpire {
+		return nil
+	}
+	switch e.ExpireType {
+	case ExpireTypeTime:
+		if e.ExpireTime <= 0 {
+			return ErrExpire
+		}
+	case ExpireTypeInterval:
+		if e.ExpireInterval < 1 || e.ExpireInterval > MaxExpireInterval {
+			return ErrExpire
+		}
+	default:
+		return ErrExpire
+	}
+	return nil
+}
+
+/*SchemeOption 获取小程序scheme码参数 */
+type SchemeOption struct {
+	JumpWxa *JumpWxa `json:"jump_wxa,omitempty"`
+	Expire
+}
+
+/*URLLinkOption 获取小程序URL Link参数 */
+type URLLinkOption struct {
+	Path       string     `json:"path,omitempty"`
+	Query      string     `json:"query,omitempty"`
+	EnvVersion EnvVersion `json:"env_version,omitempty"`
+	Expire
+}
+
+/*ShortLinkOption 获取小程序Short Link参数 */
+type ShortLinkOption struct {
+	PageURL     string `json:"page_url"`
+	PageTitle   string `json:"page_title,omitempty"`
+	IsPermanent bool   `json:"is_permanent"`
+}
+
+func validateEnvVersion(v EnvVersion) error {
+	switch v {
+	case "", EnvVersionRelease, EnvVersionTrial, EnvVersionDevelop:
+		return nil
+	}
+	return ErrEnvVersion
+}
+
+/*Validate 校验参数 */
+func (o *SchemeOption) Validate() error {
+	if o.JumpWxa != nil {
+		if err := validateEnvVersion(o.JumpWxa.EnvVersion); err != nil {
+			return err
+		}
+	}
+	return o.Expire.Validate()
+}
+
+/*Validate 校验参数 */
+func (o *URLLinkOption) Validate() error {
+	if err := validateEnvVersion(o.EnvVersion); err != nil {
+		return err
+	}
+	return o.Expire.Validate()
+}
+
+/*Validate 校验参数 */
+func (o *ShortLinkOption) Validate() error {
+	if o.PageURL == "" {
+		return ErrShortLinkPageURL
+	}
+	return nil
+}
+
+/*GenerateScheme 获取小程序scheme码,适用于短信、邮件、外部网页等拉起小程序的业务场景
+接口地址:
+POST https://api.weixin.qq.com/wxa/generatescheme?access_token=ACCESS_TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","openlink":"weixin://dl/business/?t=XTSkBZlzqmn"}
+*/
+func (a *AppCode) GenerateScheme(option *SchemeOption) core.Responder {
+	if option == nil {
+		option = &SchemeOption{}
+	}
+	if err := option.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	return a.getStream(Link(wxaGenerateScheme), option)
+}
+
+/*QueryScheme 查询小程序scheme码
+接口地址:
+POST https://api.weixin.qq.com/wxa/queryscheme?access_token=ACCESS_TOKEN
+*/
+func (a *AppCode) QueryScheme(scheme string) core.Responder {
+	return a.getStream(Link(wxaQueryScheme), map[string]string{"scheme": scheme})
+}
+
+/*GenerateURLLink 获取小程序URL Link,适用于短信、邮件、网页、微信内等拉起小程序的业务场景
+接口地址:
+POST https://api.weixin.qq.com/wxa/generate_urllink?access_token=ACCESS_TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","url_link":"https://wxaurl.cn/BQZRrcFCPvg"}
+*/
+func (a *AppCode) GenerateURLLink(option *URLLinkOption) core.Responder {
+	if option == nil {
+		option = &URLLinkOption{}
+	}
+	if err := option.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	return a.getStream(Link(wxaGenerateURLLink), option)
+}
+
+/*QueryURLLink 查询小程序URL Link
+接口地址:
+POST https://api.weixin.qq.com/wxa/query_urllink?access_token=ACCESS_TOKEN
+*/
+func (a *AppCode) QueryURLLink(urlLink string) core.Responder {
+	return a.getStream(Link(wxaQueryURLLink), map[string]string{"url_link": urlLink})
+}
+
+/*GenerateShortLink 获取小程序Short Link,适用于微信内拉起小程序的业务场景
+接口地址:
+POST https://api.weixin.qq.com/wxa/genwxashortlink?access_token=ACCESS_TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","link":"#小程序://小程序示例/IQ7b4u3hTkNjk8n"}
+*/
+func (a *AppCode) GenerateShortLink(option *ShortLinkOption) core.Responder {
+	if option == nil {
+		option = &ShortLinkOption{}
+	}
+	if err := option.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	return a.getStream(Link(wxaGenerateShortLink), option)
+}
@@ -0,0 +1,44 @@
+package mini_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/godcong/wego/app/mini"
+)
+
+// TestURLLinkOption_Validate ...
+func TestURLLinkOption_Validate(t *testing.T) {
+	opt := &mini.URLLinkOption{
+		Path:       "pages/publishHomework/publishHomework",
+		Query:      "a=1",
+		EnvVersion: mini.EnvVersionTrial,
+		Expire: mini.Expire{
+			IsExpire:       true,
+			ExpireType:     mini.ExpireTypeInterval,
+			ExpireInterval: 30,
+		},
+	}
+	if err := opt.Validate(); err != nil {
+		t.Error(err)
+	}
+	b, _ := json.Marshal(opt)
+	if string(b) != `{"path":"pages/publishHomework/publishHomework","query":"a=1","env_version":"trial","is_expire":true,"expire_type":1,"expire_interval":30}` {
+		t.Error(string(b))
+	}
+	opt.ExpireInterval = 31
+	if err := opt.Validate(); err != mini.ErrExpire {
+		t.Error(err)
+	}
+
+	scheme := &mini.SchemeOption{JumpWxa: &mini.JumpWxa{Path: "pages/index/index", EnvVersion: "beta"}}
+	if err := scheme.Validate(); err != mini.ErrEnvVersion {
+		t.Error(err)
+	}
+	if err := (&mini.ShortLinkOption{}).Validate(); err != mini.ErrShortLinkPageURL {
+		t.Error(err)
+	}
+	if err := mini.NewAppCode(cfg).GenerateShortLink(nil).Error(); err != mini.ErrShortLinkPageURL {
+		t.Error(err)
+	}
+}
@@ -1,6 +1,7 @@
 package official_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -21,7 +22,7 @@ var card = official.NewCard(config)
 //}
 
 func TestCard_CreateLandingPage(t *testing.T) {
-	resp := card.CreateLandingPage(
+	page, err := json.Marshal(
 		&official.CardLandingPage{
 			Banner:   "http://mmbiz.qpic.cn/mmbiz/iaL1LJM1mF9aRKPZJkmG8xXhiaHqkKSVMMWeN3hLut7X7hicFN",
 			Title:    "惠城优惠大派送",
@@ -35,6 +36,10 @@ func TestCard_CreateLandingPage(t *testing.T) {
 			},
 		},
 	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := card.CreateLandingPage(util.JSONToMap(page))
 	t.Log(string(resp.Bytes()))
 }
 
@@ -1,15 +1,15 @@
 package official_test
 
 import (
+	"github.com/godcong/wego"
 	"github.com/godcong/wego/app/official"
-	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/util"
 	"testing"
 )
 
 // TestCurrent_AutoReplyInfo ...
 func TestCurrent_AutoReplyInfo(t *testing.T) {
-	current := official.NewCurrent(core.C(util.Map{
+	current := official.NewCurrent(wego.C(util.Map{
 		"app_id": "wx3c69535993f4651d",
 		"secret": "f8c7a2cf0c6ed44e2c719964bbe13b1e",
 	}))
@@ -20,7 +20,7 @@ func TestCurrent_AutoReplyInfo(t *testing.T) {
 
 // TestCurrent_SelfMenuInfo ...
 func TestCurrent_SelfMenuInfo(t *testing.T) {
-	current := official.NewCurrent(core.C(util.Map{
+	current := official.NewCurrent(wego.C(util.Map{
 		"app_id": "wx3c69535993f4651d",
 		"secret": "f8c7a2cf0c6ed44e2c719964bbe13b1e",
 	}))
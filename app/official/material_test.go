@@ -6,6 +6,7 @@ import (
 	"github.com/godcong/wego/app/official"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/core/media"
+	"github.com/godcong/wego/util"
 )
 
 var material = official.NewMaterial(config)
@@ -19,7 +20,7 @@ func TestNewMaterial(t *testing.T) {
 func TestMaterial_AddNews(t *testing.T) {
 
 	var resp core.Responder
-	resp = material.AddNews([]*media.Article{
+	resp = material.AddNews(util.Map{"articles": []*media.Article{
 		{
 			Title:            "name",
 			ThumbMediaID:     "9fCk1Any5VcwmbJPzGztWMq3a1PsWv11KpgLTdM_YXgIlwdAUosdeSI_M6M7Qtwb",
@@ -29,7 +30,7 @@ func TestMaterial_AddNews(t *testing.T) {
 			Content:          "bb",
 			ContentSourceURL: "a",
 		},
-	})
+	}})
 	t.Log(string(resp.Bytes()))
 }
 
@@ -80,7 +81,7 @@ func TestMaterial_GetMaterialCount(t *testing.T) {
 
 	var resp core.Responder
 	// resp = material.Get("HIWcj9t3AI_b8qCQSu8lrTgTis9nPHNyIkIEWaDdHzY")
-	resp = material.GetMaterialCount()
+	resp = material.GetCount()
 	t.Log(string(resp.Bytes()))
 
 }
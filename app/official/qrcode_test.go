@@ -1,6 +1,7 @@
 package official_test
 
 import (
+	"github.com/godcong/wego"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/util"
 	"testing"
@@ -10,7 +11,7 @@ import (
 
 // TestQrCode_Create ...
 func TestQrCode_Create(t *testing.T) {
-	code := official.NewQrCode(core.C(util.Map{
+	code := official.NewQrCode(wego.C(util.Map{
 		"app_id": "wx3c69535993f4651d",
 		"secret": "f8c7a2cf0c6ed44e2c719964bbe13b1e",
 	}))
@@ -31,7 +32,7 @@ func TestQrCode_Create(t *testing.T) {
 
 // TestQrCode_ShowQrCode ...
 func TestQrCode_ShowQrCode(t *testing.T) {
-	code := official.NewQrCode(core.C(util.Map{
+	code := official.NewQrCode(wego.C(util.Map{
 		"app_id": "wx3c69535993f4651d",
 		"secret": "f8c7a2cf0c6ed44e2c719964bbe13b1e",
 	}))
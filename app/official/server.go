@@ -2,20 +2,76 @@ package official
 
 import (
 	"bytes"
-	"encoding/xml"
+	"errors"
+	"fmt"
 	"github.com/godcong/wego/cipher"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/core/message"
 	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 )
 
+// DefaultReplyTimeout 默认被动回复超时时间,微信服务器最多等待5秒
+const DefaultReplyTimeout = 4500 * time.Millisecond
+
+// ErrAsyncReplyQueueFull 异步回复队列已满
+var ErrAsyncReplyQueueFull = errors.New("async reply queue is full")
+
+// ErrAsyncReplyClosed 异步回复已关闭,未发送的回复被丢弃
+var ErrAsyncReplyClosed = errors.New("async reply is closed")
+
+// ErrAsyncReplyUnsendable 回复类型不能通过客服消息发送
+var ErrAsyncReplyUnsendable = errors.New("reply type can not be sent as customer service message")
+
+// AsyncErrorHandler 异步回复失败时的处理函数
+type AsyncErrorHandler func(msg *core.Message, err error)
+
+// AsyncSendFunc 异步回复的发送函数,p为客服消息内容
+type AsyncSendFunc func(p util.Map) error
+
+//customerServiceMsgTypes 客服消息支持的消息类型,transfer_customer_service等仅限被动回复
+var customerServiceMsgTypes = map[string]bool{
+	"text":            true,
+	"image":           true,
+	"voice":           true,
+	"video":           true,
+	"music":           true,
+	"news":            true,
+	"mpnews":          true,
+	"msgmenu":         true,
+	"wxcard":          true,
+	"miniprogrampage": true,
+}
+
+type asyncReply struct {
+	message *core.Message
+	result  <-chan message.Messager
+}
+
+//asyncReplier 超时回调的执行槽位及发送队列
+type asyncReplier struct {
+	timeout time.Duration
+	slots   chan struct{}
+	queue   chan *asyncReply
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
 /*Server Server */
 type Server struct {
 	*Account
-	CryptResponse bool
+	CryptResponse bool               //兼容模式下是否加密回复,默认明文回复;安全模式只有密文,回复始终加密
+	Verifier      *core.Verifier     //时间戳及nonce校验,为nil时不校验
+	Deduplicator  *core.Deduplicator //重复消息过滤,为nil时不过滤
+	AsyncError    AsyncErrorHandler
+	AsyncSend     AsyncSendFunc //异步回复发送函数,为nil时通过客服消息接口发送
+	async         *asyncReplier
+	asyncMutex    sync.RWMutex
 	//message         *core.Message
 	msgType         string
 	bizMsg          *cipher.BizMsg
@@ -50,15 +106,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// Restore the io.ReadCloser to its original state
 	req.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
 
-	//无数据直接返回
-	w.WriteHeader(http.StatusOK)
-	if len(bodyBytes) == 0 {
-		return
-	}
 	query, err := url.ParseQuery(req.URL.RawQuery)
 	//错误返回,并记录log
 	if err != nil {
 		log.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 	encryptType := query.Get("encrypt_type")
@@ -66,41 +118,68 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	nonce := query.Get("nonce")
 	msgSignature := query.Get("msg_signature")
 
+	//服务器地址验证
+	if echoStr := query.Get("echostr"); echoStr != "" {
+		s.serveEcho(w, query)
+		return
+	}
+	if err := s.verify(query); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	//无数据直接返回
+	w.WriteHeader(http.StatusOK)
+	if len(bodyBytes) == 0 {
+		return
+	}
+
+	//安全模式只包含密文,兼容模式同时包含明文和密文
+	cryptReply := false
 	if encryptType == "aes" {
 		log.Debug(ts, nonce, msgSignature, string(bodyBytes))
-		bodyBytes, err = s.bizMsg.Decrypt(string(bodyBytes), msgSignature, ts, nonce)
+		compatible := util.XMLToMap(bodyBytes).Has("MsgType")
+		decrypted, err := s.bizMsg.Decrypt(string(bodyBytes), msgSignature, ts, nonce)
 		//错误返回,并记录log
-		if err != nil {
+		if err != nil && !compatible {
 			log.Error(err)
 			return
 		}
+		if err == nil {
+			bodyBytes = decrypted
+			//安全模式下公众平台只接受密文回复,CryptResponse仅作用于兼容模式
+			cryptReply = !compatible || s.CryptResponse
+		}
 	}
 
-	message := new(core.Message)
 	log.Debug(string(bodyBytes))
-	err = xml.Unmarshal(bodyBytes, message)
+	message, err := core.ParseMessage(bodyBytes, false)
 	//错误返回,并记录log
 	if err != nil {
 		log.Error(err)
 		return
 	}
-	result := s.CallbackFunc(message)
-
-	rltXML, err = result.ToXML()
+	rltXML, err = s.reply(message)
 	//错误返回,并记录log
 	if err != nil {
 		log.Error(err)
 		return
 	}
+	//无回复时返回success
+	if len(rltXML) == 0 {
+		_, _ = w.Write([]byte("success"))
+		return
+	}
 
-	//if encryptType == "aes" {
-	//	tmpStr, err := s.bizMsg.RSAEncrypt(string(rltXML), ts, nonce)
-	//	if err != nil {
-	//		log.Error(err)
-	//		return
-	//	}
-	//	rltXML = []byte(tmpStr)
-	//}
+	if cryptReply {
+		tmpStr, err := s.bizMsg.Encrypt(string(rltXML), ts, nonce)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		rltXML = []byte(tmpStr)
+	}
 	if s.msgType == "xml" {
 		header := w.Header()
 		if val := header["Content-Type"]; len(val) == 0 {
@@ -113,10 +192,71 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 	log.Debug(string(rltXML))
-	w.Write(rltXML)
+	_, _ = w.Write(rltXML)
 	return
 }
 
+// reply 执行回调并返回回复内容,重复推送的消息直接返回首次处理的回复
+func (s *Server) reply(msg *core.Message) ([]byte, error) {
+	if s.Deduplicator != nil {
+		if rlt, dup := s.Deduplicator.Check(msg); dup {
+			return rlt, nil
+		}
+	}
+	var rltXML []byte
+	var err error
+	if result := s.callbackWithTimeout(msg); result != nil {
+		rltXML, err = result.ToXML()
+	}
+	if s.Deduplicator != nil && err == nil {
+		s.Deduplicator.Save(msg, rltXML)
+	}
+	return rltXML, err
+}
+
+// verify 校验请求签名,时间戳及nonce
+func (s *Server) verify(query url.Values) error {
+	ts := query.Get("timestamp")
+	nonce := query.Get("nonce")
+	if !s.bizMsg.VerifySignature(query.Get("signature"), ts, nonce) {
+		return core.ErrInvalidSignature
+	}
+	if s.Verifier != nil {
+		return s.Verifier.Verify(ts, nonce)
+	}
+	return nil
+}
+
+// serveEcho 处理服务器地址验证,安全模式下echostr需要解密
+func (s *Server) serveEcho(w http.ResponseWriter, query url.Values) {
+	ts := query.Get("timestamp")
+	nonce := query.Get("nonce")
+	echoStr := query.Get("echostr")
+	if msgSignature := query.Get("msg_signature"); msgSignature != "" {
+		echo, err := s.bizMsg.DecryptEchoStr(echoStr, msgSignature, ts, nonce)
+		if err != nil {
+			log.Error(err)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		echoStr = string(echo)
+		if s.Verifier != nil {
+			err = s.Verifier.Verify(ts, nonce)
+		}
+		if err != nil {
+			log.Error(err)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	} else if err := s.verify(query); err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(echoStr))
+}
+
 /*CallbackFunc message回调函数*/
 func (s *Server) CallbackFunc(msg *core.Message) message.Messager {
 	var result message.Messager
@@ -136,12 +276,181 @@ func (s *Server) CallbackFunc(msg *core.Message) message.Messager {
 	return result
 }
 
+/*EnableAsyncReply 回调超过timeout未返回时先回复success,结果由workers个协程通过客服消息异步发送
+同时执行的超时回调不超过workers*10个,槽位用尽时回调在请求中同步执行;不再使用时调用Close停止
+*/
+func (s *Server) EnableAsyncReply(timeout time.Duration, workers int) *Server {
+	if timeout <= 0 {
+		timeout = DefaultReplyTimeout
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	s.asyncMutex.Lock()
+	defer s.asyncMutex.Unlock()
+	if s.async != nil {
+		s.async.timeout = timeout
+		return s
+	}
+	a := &asyncReplier{
+		timeout: timeout,
+		slots:   make(chan struct{}, workers*10),
+		queue:   make(chan *asyncReply, workers*10),
+		done:    make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		a.wg.Add(1)
+		go s.asyncWorker(a)
+	}
+	s.async = a
+	return s
+}
+
+/*Close 停止异步回复,等待发送协程退出,队列中未发送的回复通过AsyncError报告ErrAsyncReplyClosed */
+func (s *Server) Close() error {
+	s.asyncMutex.Lock()
+	a := s.async
+	s.async = nil
+	s.asyncMutex.Unlock()
+	if a == nil {
+		return nil
+	}
+	close(a.done)
+	a.wg.Wait()
+	for {
+		select {
+		case reply := <-a.queue:
+			s.asyncFailed(reply.message, ErrAsyncReplyClosed)
+		default:
+			return nil
+		}
+	}
+}
+
+// callbackWithTimeout 执行回调,超时后返回nil并将结果交给异步队列
+func (s *Server) callbackWithTimeout(msg *core.Message) message.Messager {
+	s.asyncMutex.RLock()
+	a := s.async
+	var timeout time.Duration
+	if a != nil {
+		timeout = a.timeout
+	}
+	s.asyncMutex.RUnlock()
+	if a == nil {
+		return s.CallbackFunc(msg)
+	}
+	select {
+	case a.slots <- struct{}{}:
+	default:
+		return s.CallbackFunc(msg)
+	}
+	ch := make(chan message.Messager, 1)
+	go func() {
+		defer func() {
+			<-a.slots
+			if e := recover(); e != nil {
+				log.Error(e)
+				ch <- nil
+			}
+		}()
+		ch <- s.CallbackFunc(msg)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case result := <-ch:
+		return result
+	case <-timer.C:
+	}
+
+	select {
+	case a.queue <- &asyncReply{message: msg, result: ch}:
+	default:
+		s.asyncFailed(msg, ErrAsyncReplyQueueFull)
+	}
+	return nil
+}
+
+func (s *Server) asyncWorker(a *asyncReplier) {
+	defer a.wg.Done()
+	for {
+		var reply *asyncReply
+		select {
+		case <-a.done:
+			return
+		case reply = <-a.queue:
+		}
+		var result message.Messager
+		select {
+		case <-a.done:
+			s.asyncFailed(reply.message, ErrAsyncReplyClosed)
+			return
+		case result = <-reply.result:
+		}
+		if result == nil {
+			continue
+		}
+		if err := s.sendAsyncReply(reply.message, result); err != nil {
+			s.asyncFailed(reply.message, err)
+		}
+	}
+}
+
+// sendAsyncReply 将回复转为客服消息发送给消息发送者,被动回复使用ToCustomMessage转换
+func (s *Server) sendAsyncReply(msg *core.Message, result message.Messager) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%v", e)
+		}
+	}()
+	var p util.Map
+	if reply, b := result.(*message.Reply); b {
+		if err := reply.Validate(); err != nil {
+			return err
+		}
+		p = reply.ToCustomMessage()
+	} else {
+		//Text等消息的ToJSON即为客服消息格式
+		b, err := result.ToJSON()
+		if err != nil {
+			return err
+		}
+		p = util.JSONToMap(b)
+	}
+	if !customerServiceMsgTypes[p.GetString("msgtype")] {
+		return ErrAsyncReplyUnsendable
+	}
+	p.Set("touser", msg.FromUserName.Value)
+	if s.AsyncSend != nil {
+		return s.AsyncSend(p)
+	}
+	m, err := newCustomerService(s.Account).MessageSend(p).Result()
+	if err != nil {
+		return err
+	}
+	if code, b := m.GetInt64("errcode"); b && code != 0 {
+		return errors.New(m.GetString("errmsg"))
+	}
+	return nil
+}
+
+func (s *Server) asyncFailed(msg *core.Message, err error) {
+	log.Error(err)
+	if s.AsyncError != nil {
+		s.AsyncError(msg, err)
+	}
+}
+
 func newServer(account *Account) *Server {
 	token := account.GetString("token")
 	key := account.GetString("aes_key")
 	id := account.GetString("app_id")
 
 	return &Server{
+		Account:         account,
+		Verifier:        core.NewVerifier(id),
+		Deduplicator:    core.NewDeduplicator(id),
 		msgType:         "xml",
 		bizMsg:          cipher.NewBizMsg(token, key, id),
 		defaultCallback: []core.MessageCallback{},
@@ -0,0 +1,124 @@
+package official_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godcong/wego"
+	"github.com/godcong/wego/app/official"
+	"github.com/godcong/wego/cipher"
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+	"github.com/godcong/wego/util"
+)
+
+var textRequest = `<xml><ToUserName><![CDATA[gh_56870ffd193b]]></ToUserName><FromUserName><![CDATA[oLyBi0hSYhggnD-kOIms0IzZFqrc]]></FromUserName><CreateTime>1524409354</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hello]]></Content><MsgId>6547288321577417974</MsgId></xml>`
+
+// TestServer_EnableAsyncReply ...
+func TestServer_EnableAsyncReply(t *testing.T) {
+	server := official.NewServer(wego.C(util.Map{
+		"app_id": "wxbafed7010e0f4531",
+		"token":  "godcong",
+	}))
+	errs := make(chan error, 1)
+	server.AsyncError = func(msg *core.Message, err error) {
+		errs <- err
+	}
+	sent := make(chan util.Map, 1)
+	server.AsyncSend = func(p util.Map) error {
+		sent <- p
+		return nil
+	}
+	server.EnableAsyncReply(100*time.Millisecond, 1)
+	defer server.Close()
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		time.Sleep(300 * time.Millisecond)
+		if msg.Content.Value == "transfer" {
+			return message.NewTransferReply(&msg.Message, "")
+		}
+		return message.NewTextReply(&msg.Message, "slow")
+	})
+
+	serve := func(msgID, content string) (string, time.Duration) {
+		ts, nonce := strconv.FormatInt(time.Now().Unix(), 10), "542437598"
+		query := url.Values{
+			"timestamp": {ts},
+			"nonce":     {nonce},
+			"signature": {cipher.NewBizMsg("godcong", "", "").Signature(ts, nonce)},
+		}
+		body := strings.Replace(textRequest, "6547288321577417974", msgID, 1)
+		body = strings.Replace(body, "hello", content, 1)
+		req := httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+		start := time.Now()
+		server.ServeHTTP(w, req)
+		return w.Body.String(), time.Since(start)
+	}
+
+	if rlt, d := serve("6547288321577417974", "hello"); rlt != "success" || d > 250*time.Millisecond {
+		t.Error(rlt, d)
+	}
+	select {
+	case p := <-sent:
+		if p.GetString("touser") != "oLyBi0hSYhggnD-kOIms0IzZFqrc" || p.GetString("msgtype") != "text" {
+			t.Error(p)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("async reply not sent")
+	}
+
+	//转发客服等仅限被动回复的类型不能异步发送
+	if rlt, _ := serve("6547288321577417975", "transfer"); rlt != "success" {
+		t.Error(rlt)
+	}
+	select {
+	case err := <-errs:
+		if err != official.ErrAsyncReplyUnsendable {
+			t.Error(err)
+		}
+	case p := <-sent:
+		t.Error(p)
+	case <-time.After(5 * time.Second):
+		t.Fatal("unsendable reply not reported")
+	}
+}
+
+// TestServer_Close ...
+func TestServer_Close(t *testing.T) {
+	server := official.NewServer(wego.C(util.Map{
+		"app_id": "wxbafed7010e0f4531",
+		"token":  "godcong",
+	}))
+	server.AsyncSend = func(p util.Map) error {
+		return nil
+	}
+	server.EnableAsyncReply(50*time.Millisecond, 1)
+	server.RegisterCallback(func(msg *core.Message) message.Messager {
+		time.Sleep(100 * time.Millisecond)
+		return message.NewTextReply(&msg.Message, "closed")
+	})
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	//关闭后回调在请求中同步执行
+	ts, nonce := strconv.FormatInt(time.Now().Unix(), 10), "542437599"
+	query := url.Values{
+		"timestamp": {ts},
+		"nonce":     {nonce},
+		"signature": {cipher.NewBizMsg("godcong", "", "").Signature(ts, nonce)},
+	}
+	body := strings.Replace(textRequest, "6547288321577417974", "6547288321577417976", 1)
+	req := httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "closed") {
+		t.Error(w.Body.String())
+	}
+}
@@ -9,7 +9,7 @@ import (
 
 // TestNewTemplate ...
 func TestNewTemplate(t *testing.T) {
-	t0 := official.NewTemplate(cfg)
+	t0 := official.NewTemplate(config)
 
 	//testTemplate_SetIndustry(t, t0)
 	//testTemplate_GetIndustry(t, t0)
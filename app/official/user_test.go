@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/godcong/wego/app/official"
+	"github.com/godcong/wego/util"
 )
 
 var u = official.NewUser(config)
@@ -18,7 +19,7 @@ func TestUser_UpdateRemark(t *testing.T) {
 }
 
 func TestUser_UserInfo(t *testing.T) {
-	resp := u.UserInfo("oLyBi0tDnybg0WFkhKsn5HRetX1I", "zh_CN")
+	resp := u.UserInfo("oLyBi0tDnybg0WFkhKsn5HRetX1I", util.Map{"lang": "zh_CN"})
 	t.Log(*resp)
 }
 
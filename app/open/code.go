@@ -0,0 +1,284 @@
+package open
+
+import (
+	"encoding/json"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+/*VisitStatus 小程序服务状态 */
+type VisitStatus string
+
+/*VisitStatus types */
+const (
+	VisitStatusOpen  VisitStatus = "open"  //开启服务
+	VisitStatusClose VisitStatus = "close" //暂停服务
+)
+
+// MaxAuditItems 提交审核时最多可填写的审核项
+const MaxAuditItems = 5
+
+/*CommitOption 上传代码参数 */
+type CommitOption struct {
+	TemplateID  int64       `json:"template_id"`
+	ExtJSON     interface{} `json:"-"` //第三方自定义配置,可为string或可json序列化的结构
+	UserVersion string      `json:"user_version"`
+	UserDesc    string      `json:"user_desc"`
+}
+
+/*ToMap 转换为请求参数,ext_json序列化为字符串 */
+func (o *CommitOption) ToMap() (util.Map, error) {
+	ext := ""
+	switch v := o.ExtJSON.(type) {
+	case nil:
+	case string:
+		ext = v
+	case []byte:
+		ext = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		ext = string(b)
+	}
+	return util.Map{
+		"template_id":  o.TemplateID,
+		"ext_json":     ext,
+		"user_version": o.UserVersion,
+		"user_desc":    o.UserDesc,
+	}, nil
+}
+
+/*AuditItem 审核项,类目信息通过GetCategory获取,页面通过GetPage获取 */
+type AuditItem struct {
+	Address     string `json:"address,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	FirstClass  string `json:"first_class,omitempty"`
+	SecondClass string `json:"second_class,omitempty"`
+	ThirdClass  string `json:"third_class,omitempty"`
+	FirstID     int64  `json:"first_id,omitempty"`
+	SecondID    int64  `json:"second_id,omitempty"`
+	ThirdID     int64  `json:"third_id,omitempty"`
+	Title       string `json:"title,omitempty"`
+}
+
+/*SubmitAuditOption 提交审核参数 */
+type SubmitAuditOption struct {
+	ItemList      []*AuditItem `json:"item_list,omitempty"`
+	VersionDesc   string       `json:"version_desc,omitempty"`
+	FeedbackInfo  string       `json:"feedback_info,omitempty"`
+	FeedbackStuff string       `json:"feedback_stuff,omitempty"` //用|分隔的media_id列表
+}
+
+/*Validate 校验参数 */
+func (o *SubmitAuditOption) Validate() error {
+	if len(o.ItemList) == 0 || len(o.ItemList) > MaxAuditItems {
+		return ErrAuditItemList
+	}
+	return nil
+}
+
+/*Code 第三方平台代小程序实现代码管理
+所有接口使用授权方(小程序)的authorizer_access_token调用,审核结果通过weapp_audit_success,
+weapp_audit_fail,weapp_audit_delay事件推送至授权方的消息与事件接收URL,
+可在Server回调中通过core.Message.DecodeEvent解析为*message.WeappAuditEvent */
+type Code struct {
+	*Platform
+	authorizerAccessToken string
+}
+
+func newCode(platform *Platform) interface{} {
+	return &Code{
+		Platform: platform,
+	}
+}
+
+/*NewCode NewCode */
+func NewCode(config *core.Config) *Code {
+	return newCode(NewOpenPlatform(config)).(*Code)
+}
+
+/*Authorizer 返回使用授权方authorizer_access_token的Code */
+func (c *Code) Authorizer(accessToken string) *Code {
+	return &Code{
+		Platform:              c.Platform,
+		authorizerAccessToken: accessToken,
+	}
+}
+
+/*Commit 上传小程序代码
+接口地址:
+POST https://api.weixin.qq.com/wxa/commit?access_token=TOKEN
+参数	必填	说明
+template_id	是	代码库中的代码模板ID
+ext_json	是	第三方自定义的配置
+user_version	是	代码版本号，开发者可自定义（长度不要超过64个字符）
+user_desc	是	代码描述，开发者可自定义
+*/
+func (c *Code) Commit(option *CommitOption) core.Responder {
+	log.Debug("Code|Commit", option)
+	p, err := option.ToMap()
+	if err != nil {
+		return core.Err(nil, err)
+	}
+	return c.post(wxaCommit, p)
+}
+
+/*GetPage 获取已上传的代码的页面列表
+接口地址:
+GET https://api.weixin.qq.com/wxa/get_page?access_token=TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","page_list":["index/index","page/list"]}
+*/
+func (c *Code) GetPage() core.Responder {
+	return c.get(wxaGetPage, nil)
+}
+
+/*GetCategory 获取授权小程序帐号已设置的类目
+接口地址:
+GET https://api.weixin.qq.com/wxa/get_category?access_token=TOKEN
+*/
+func (c *Code) GetCategory() core.Responder {
+	return c.get(wxaGetCategory, nil)
+}
+
+/*GetQrcode 获取体验版二维码,path为空时使用默认页面
+接口地址:
+GET https://api.weixin.qq.com/wxa/get_qrcode?access_token=TOKEN&path=page%2Findex%3Faction%3D1
+*/
+func (c *Code) GetQrcode(path string) core.Responder {
+	var query util.Map
+	if path != "" {
+		query = util.Map{"path": path}
+	}
+	return c.get(wxaGetQrcode, query)
+}
+
+/*SubmitAudit 将第三方提交的代码包提交审核
+接口地址:
+POST https://api.weixin.qq.com/wxa/submit_audit?access_token=TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","auditid":1234567}
+*/
+func (c *Code) SubmitAudit(option *SubmitAuditOption) core.Responder {
+	log.Debug("Code|SubmitAudit", option)
+	if option == nil {
+		option = &SubmitAuditOption{}
+	}
+	if err := option.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	return c.post(wxaSubmitAudit, option)
+}
+
+/*GetAuditStatus 查询某个指定版本的审核状态
+接口地址:
+POST https://api.weixin.qq.com/wxa/get_auditstatus?access_token=TOKEN
+成功:
+{"errcode":0,"errmsg":"ok","status":1,"reason":"帐号信息不合规范","screenshot":"xx|yy|zz"}
+status:0为审核成功,1为审核被拒绝,2为审核中,3为已撤回,4为审核延后
+*/
+func (c *Code) GetAuditStatus(auditID int64) core.Responder {
+	return c.post(wxaGetAuditStatus, util.Map{"auditid": auditID})
+}
+
+/*GetLatestAuditStatus 查询最新一次提交的审核状态
+接口地址:
+GET https://api.weixin.qq.com/wxa/get_latest_auditstatus?access_token=TOKEN
+*/
+func (c *Code) GetLatestAuditStatus() core.Responder {
+	return c.get(wxaGetLatestAuditStatus, nil)
+}
+
+/*UndoCodeAudit 小程序审核撤回,单个帐号每天审核撤回次数最多不超过1次
+接口地址:
+GET https://api.weixin.qq.com/wxa/undocodeaudit?access_token=TOKEN
+*/
+func (c *Code) UndoCodeAudit() core.Responder {
+	return c.get(wxaUndoCodeAudit, nil)
+}
+
+/*Release 发布已通过审核的小程序
+接口地址:
+POST https://api.weixin.qq.com/wxa/release?access_token=TOKEN
+*/
+func (c *Code) Release() core.Responder {
+	return c.post(wxaRelease, util.Map{})
+}
+
+/*RevertCodeRelease 版本回退,只能回退到上一个线上版本
+接口地址:
+GET https://api.weixin.qq.com/wxa/revertcoderelease?access_token=TOKEN
+*/
+func (c *Code) RevertCodeRelease() core.Responder {
+	return c.get(wxaRevertCodeRelease, nil)
+}
+
+/*GrayRelease 分阶段发布
+接口地址:
+POST https://api.weixin.qq.com/wxa/grayrelease?access_token=TOKEN
+参数	必填	说明
+gray_percentage	是	灰度的百分比，1到100的整数
+*/
+func (c *Code) GrayRelease(percentage int) core.Responder {
+	if percentage < 1 || percentage > 100 {
+		return core.Err(nil, ErrGrayPercentage)
+	}
+	return c.post(wxaGrayRelease, util.Map{"gray_percentage": percentage})
+}
+
+/*RevertGrayRelease 取消分阶段发布
+接口地址:
+GET https://api.weixin.qq.com/wxa/revertgrayrelease?access_token=TOKEN
+*/
+func (c *Code) RevertGrayRelease() core.Responder {
+	return c.get(wxaRevertGrayRelease, nil)
+}
+
+/*GetGrayReleasePlan 查询当前分阶段发布详情
+接口地址:
+GET https://api.weixin.qq.com/wxa/getgrayreleaseplan?access_token=TOKEN
+*/
+func (c *Code) GetGrayReleasePlan() core.Responder {
+	return c.get(wxaGetGrayReleasePlan, nil)
+}
+
+/*ChangeVisitStatus 修改小程序线上代码的可见状态
+接口地址:
+POST https://api.weixin.qq.com/wxa/change_visitstatus?access_token=TOKEN
+*/
+func (c *Code) ChangeVisitStatus(action VisitStatus) core.Responder {
+	if action != VisitStatusOpen && action != VisitStatusClose {
+		return core.Err(nil, ErrVisitStatus)
+	}
+	return c.post(wxaChangeVisitStatus, util.Map{"action": action})
+}
+
+func (c *Code) keyMap() (util.Map, error) {
+	if c.authorizerAccessToken == "" {
+		return nil, ErrAuthorizerAccessToken
+	}
+	return util.Map{"access_token": c.authorizerAccessToken}, nil
+}
+
+func (c *Code) get(url string, query util.Map) core.Responder {
+	key, err := c.keyMap()
+	if err != nil {
+		return core.Err(nil, err)
+	}
+	for k, v := range query {
+		key.Set(k, v)
+	}
+	return core.Get(Link(url), key)
+}
+
+func (c *Code) post(url string, body interface{}) core.Responder {
+	key, err := c.keyMap()
+	if err != nil {
+		return core.Err(nil, err)
+	}
+	return core.PostJSON(Link(url), key, body)
+}
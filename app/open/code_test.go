@@ -0,0 +1,40 @@
+package open_test
+
+import (
+	"testing"
+
+	"github.com/godcong/wego"
+	"github.com/godcong/wego/app/open"
+	"github.com/godcong/wego/util"
+)
+
+// TestCommitOption_ToMap ...
+func TestCommitOption_ToMap(t *testing.T) {
+	opt := &open.CommitOption{
+		TemplateID:  1,
+		ExtJSON:     util.Map{"extAppid": "wxf9c4501a76931b33", "ext": util.Map{"name": "wechat"}},
+		UserVersion: "V1.0",
+		UserDesc:    "test",
+	}
+	m, err := opt.ToMap()
+	if err != nil || m.GetString("ext_json") != `{"ext":{"name":"wechat"},"extAppid":"wxf9c4501a76931b33"}` {
+		t.Error(m, err)
+	}
+	if resp := open.NewCode(wego.C(util.Map{})).GetPage(); resp.Error() != open.ErrAuthorizerAccessToken {
+		t.Error(resp.Error())
+	}
+}
+
+// TestSubmitAuditOption_Validate ...
+func TestSubmitAuditOption_Validate(t *testing.T) {
+	opt := &open.SubmitAuditOption{}
+	if err := opt.Validate(); err != open.ErrAuditItemList {
+		t.Error(err)
+	}
+	for i := 0; i <= open.MaxAuditItems; i++ {
+		opt.ItemList = append(opt.ItemList, &open.AuditItem{Address: "pages/index/index", Tag: "工具"})
+		if err := opt.Validate(); (err == nil) != (i < open.MaxAuditItems) {
+			t.Error(i, err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package open
+
+import "errors"
+
+const domain = "https://api.weixin.qq.com"
+
+// ErrAuthorizerAccessToken 缺少授权方access_token
+var ErrAuthorizerAccessToken = errors.New("authorizer access token is empty")
+
+// ErrAuditItemList 审核项数量错误
+var ErrAuditItemList = errors.New("audit item_list must contain 1 to 5 items")
+
+// ErrGrayPercentage 灰度百分比错误
+var ErrGrayPercentage = errors.New("gray_percentage must be between 1 and 100")
+
+// ErrVisitStatus 服务状态错误
+var ErrVisitStatus = errors.New("visit status action must be open or close")
+
+const wxaCommit = "/wxa/commit"
+const wxaGetPage = "/wxa/get_page"
+const wxaGetCategory = "/wxa/get_category"
+const wxaGetQrcode = "/wxa/get_qrcode"
+const wxaSubmitAudit = "/wxa/submit_audit"
+const wxaGetAuditStatus = "/wxa/get_auditstatus"
+const wxaGetLatestAuditStatus = "/wxa/get_latest_auditstatus"
+const wxaUndoCodeAudit = "/wxa/undocodeaudit"
+const wxaRelease = "/wxa/release"
+const wxaRevertCodeRelease = "/wxa/revertcoderelease"
+const wxaGrayRelease = "/wxa/grayrelease"
+const wxaRevertGrayRelease = "/wxa/revertgrayrelease"
+const wxaGetGrayReleasePlan = "/wxa/getgrayreleaseplan"
+const wxaChangeVisitStatus = "/wxa/change_visitstatus"
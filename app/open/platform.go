@@ -40,18 +40,30 @@ func neOpenPlatform(config *core.Config, p util.Map) *Platform {
 	}
 }
 
-//NewOfficialPlatform return a official Platform
-func NewOpenPlatform(config *core.Config, v ...interface{}) *Platform {
-	client := core.ClientGet(v)
+//NewOpenPlatform return a open Platform
+func NewOpenPlatform(config *core.Config) *Platform {
 	accessToken := newAccessToken(util.Map{
 		"grant_type": "client_credential",
 		"appid":      config.GetString("app_id"),
 		"secret":     config.GetString("secret"),
 	})
-	accessToken.SetClient(client)
 
 	platform := neOpenPlatform(config, util.Map{})
-	platform.SetClient(client)
 	platform.SetAccessToken(accessToken)
 	return platform
 }
+
+// Code ...
+func (p *Platform) Code() *Code {
+	obj, b := p.Sub["Code"]
+	if !b {
+		obj = newCode(p)
+		p.Sub["Code"] = obj
+	}
+	return obj.(*Code)
+}
+
+//Link 拼接地址
+func Link(url string) string {
+	return core.Splice(core.DefaultConfig().GetStringD("domain.open_platform.url", domain), url)
+}
@@ -0,0 +1,69 @@
+package payment
+
+/*银行编号列表
+企业付款到银行卡(bank_code)使用的开户行编号
+*/
+const (
+	BankICBC  = "1002" //工商银行
+	BankABC   = "1005" //农业银行
+	BankBOC   = "1026" //中国银行
+	BankCCB   = "1003" //建设银行
+	BankCMB   = "1001" //招商银行
+	BankPSBC  = "1066" //邮储银行
+	BankBCOM  = "1020" //交通银行
+	BankSPDB  = "1004" //浦发银行
+	BankCMBC  = "1006" //民生银行
+	BankCIB   = "1009" //兴业银行
+	BankPAB   = "1010" //平安银行
+	BankCITIC = "1021" //中信银行
+	BankHXB   = "1025" //华夏银行
+	BankCGB   = "1027" //广发银行
+	BankCEB   = "1022" //光大银行
+	BankBOB   = "4836" //北京银行
+	BankNBCB  = "1056" //宁波银行
+)
+
+var bankNames = map[string]string{
+	BankICBC:  "工商银行",
+	BankABC:   "农业银行",
+	BankBOC:   "中国银行",
+	BankCCB:   "建设银行",
+	BankCMB:   "招商银行",
+	BankPSBC:  "邮储银行",
+	BankBCOM:  "交通银行",
+	BankSPDB:  "浦发银行",
+	BankCMBC:  "民生银行",
+	BankCIB:   "兴业银行",
+	BankPAB:   "平安银行",
+	BankCITIC: "中信银行",
+	BankHXB:   "华夏银行",
+	BankCGB:   "广发银行",
+	BankCEB:   "光大银行",
+	BankBOB:   "北京银行",
+	BankNBCB:  "宁波银行",
+}
+
+// BankName 根据银行编号获取银行名称
+func BankName(code string) (string, bool) {
+	name, b := bankNames[code]
+	return name, b
+}
+
+// BankCode 根据银行名称获取银行编号
+func BankCode(name string) (string, bool) {
+	for code, n := range bankNames {
+		if n == name {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// BankCodes 获取全部支持的银行编号及名称
+func BankCodes() map[string]string {
+	codes := make(map[string]string, len(bankNames))
+	for code, name := range bankNames {
+		codes[code] = name
+	}
+	return codes
+}
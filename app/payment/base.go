@@ -1,7 +1,11 @@
 package payment
 
 import (
+	"errors"
+	"time"
+
 	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
 	"github.com/godcong/wego/util"
 )
 
@@ -45,6 +49,116 @@ func (p *Payment) Pay(maps util.Map) core.Responder {
 	return p.Request(payMicroPay, maps)
 }
 
+/*MicroPayPolicy 刷卡支付查询及撤销策略 */
+type MicroPayPolicy struct {
+	QueryInterval     time.Duration //查询订单间隔
+	QueryTimeout      time.Duration //查询订单最长时间,超时后撤销
+	ReverseBackoff    time.Duration //首次重试撤销前的等待时间,之后每次翻倍
+	ReverseMaxBackoff time.Duration //重试撤销的最长等待时间
+	ReverseLimit      int           //撤销最多次数
+}
+
+/*DefaultMicroPayPolicy 微信推荐的刷卡支付策略:每5秒查询一次,30秒后撤销,撤销最多10次 */
+func DefaultMicroPayPolicy() *MicroPayPolicy {
+	return &MicroPayPolicy{
+		QueryInterval:     microPayQueryInterval,
+		QueryTimeout:      microPayQueryTimeout,
+		ReverseBackoff:    microPayReverseBackoff,
+		ReverseMaxBackoff: microPayReverseMaxBackoff,
+		ReverseLimit:      microPayReverseLimit,
+	}
+}
+
+/*PayByAuthCode 刷卡支付(自动轮询及撤销)
+按微信推荐流程处理刷卡支付:
+1.提交刷卡支付,成功则直接返回
+2.返回USERPAYING/SYSTEMERROR/BANKERROR,请求失败或return_code不为SUCCESS(支付结果未知)时,每隔5秒查询订单,最长30秒
+3.查询结果为SUCCESS则返回订单,其他状态或超时则撤销订单
+4.撤销请求失败或返回recall=Y时退避后重新撤销,最多10次;recall=N时不再重试
+返回:
+支付成功时返回订单数据,否则返回错误(撤销成功为ErrMicroPayReversed)
+*/
+func (p *Payment) PayByAuthCode(maps util.Map) (util.Map, error) {
+	return p.PayByAuthCodeWithPolicy(maps, nil)
+}
+
+/*PayByAuthCodeWithPolicy 按指定策略进行刷卡支付,policy为nil时使用DefaultMicroPayPolicy */
+func (p *Payment) PayByAuthCodeWithPolicy(maps util.Map, policy *MicroPayPolicy) (util.Map, error) {
+	no := maps.GetString("out_trade_no")
+	if no == "" {
+		return nil, ErrNilOutTradeNo
+	}
+	if policy == nil {
+		policy = DefaultMicroPayPolicy()
+	}
+
+	rlt, err := p.Pay(maps).Result()
+	if err == nil && rlt.GetString("return_code") == "SUCCESS" {
+		if rlt.GetString("result_code") == "SUCCESS" {
+			return rlt, nil
+		}
+		switch rlt.GetString("err_code") {
+		case "USERPAYING", "SYSTEMERROR", "BANKERROR":
+		default:
+			return rlt, errors.New(rlt.GetString("err_code_des"))
+		}
+	}
+	log.Debug("PayByAuthCode|pay", rlt, err)
+
+	if rlt, b := p.microPayQuery(no, policy); b {
+		return rlt, nil
+	}
+	return p.microPayReverse(no, policy)
+}
+
+func (p *Payment) microPayQuery(no string, policy *MicroPayPolicy) (util.Map, bool) {
+	deadline := time.Now().Add(policy.QueryTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(policy.QueryInterval)
+		rlt, err := p.Order().QueryByOutTradeNumber(no).Result()
+		if err != nil || rlt.GetString("return_code") != "SUCCESS" ||
+			rlt.GetString("result_code") != "SUCCESS" {
+			log.Error("PayByAuthCode|query", rlt, err)
+			continue
+		}
+		switch rlt.GetString("trade_state") {
+		case "SUCCESS":
+			return rlt, true
+		case "USERPAYING":
+			continue
+		default:
+			return rlt, false
+		}
+	}
+	return nil, false
+}
+
+func (p *Payment) microPayReverse(no string, policy *MicroPayPolicy) (util.Map, error) {
+	var rlt util.Map
+	var err error
+	backoff := policy.ReverseBackoff
+	for i := 0; i < policy.ReverseLimit; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > policy.ReverseMaxBackoff {
+				backoff = policy.ReverseMaxBackoff
+			}
+		}
+		rlt, err = p.Reverse().ByOutTradeNumber(no).Result()
+		if err != nil || rlt.GetString("return_code") != "SUCCESS" {
+			log.Error("PayByAuthCode|reverse", rlt, err)
+			continue
+		}
+		if rlt.GetString("result_code") == "SUCCESS" {
+			return rlt, ErrMicroPayReversed
+		}
+		if rlt.GetString("recall") != "Y" {
+			break
+		}
+	}
+	return rlt, ErrMicroPayReverseFailed
+}
+
 /*AuthCodeToOpenid 通过授权码查询公众号Openid
 接口链接: https://api.mch.weixin.qq.com/tools/authcodetoopenid
 通过授权码查询公众号Openid，调用查询后，该授权码只能由此商户号发起扣款，直至授权码更新。
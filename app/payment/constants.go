@@ -1,6 +1,9 @@
 package payment
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 const domain = "https://api.mch.weixin.qq.com"
 
@@ -20,6 +23,15 @@ const payRefundQuery = "/pay/refundquery"
 const payReverse = "/secapi/pay/reverse"
 const payRefund = "/secapi/pay/refund"
 
+const profitSharingAddReceiver = "/pay/profitsharingaddreceiver"
+const profitSharingRemoveReceiver = "/pay/profitsharingremovereceiver"
+const profitSharing = "/secapi/pay/profitsharing"
+const multiProfitSharing = "/secapi/pay/multiprofitsharing"
+const profitSharingFinish = "/secapi/pay/profitsharingfinish"
+const profitSharingQuery = "/pay/profitsharingquery"
+const profitSharingReturn = "/secapi/pay/profitsharingreturn"
+const profitSharingReturnQuery = "/pay/profitsharingreturnquery"
+
 const mchSubmchmanage = "/secapi/mch/submchmanage"
 const mchModifymchinfo = "/secapi/mch/modifymchinfo"
 const mktAddrecommendconf = "/secapi/mkt/addrecommendconf"
@@ -40,6 +52,15 @@ const mmpaysptransPayBank = "/mmpaysptrans/pay_bank"
 
 const authCodeToOpenidURLSuffix = "/tools/authcodetoopenid"
 
+// DefaultPublicKeyTTL 缓存RSA加密公钥的默认时长
+const DefaultPublicKeyTTL = 24 * time.Hour
+
+const microPayQueryInterval = 5 * time.Second
+const microPayQueryTimeout = 30 * time.Second
+const microPayReverseBackoff = time.Second
+const microPayReverseMaxBackoff = 8 * time.Second
+const microPayReverseLimit = 10
+
 const sandboxURLSuffix = "/sandboxnew"
 const sandboxSignKeyURLSuffix = sandboxURLSuffix + "/pay/getsignkey"
 
@@ -48,3 +69,57 @@ const BizPayURL = "weixin://wxpay/bizpayurl?"
 
 // ErrNilNotifyCallback ...
 var ErrNilNotifyCallback = errors.New("nil notify callback")
+
+// ErrNilOutTradeNo ...
+var ErrNilOutTradeNo = errors.New("nil out_trade_no")
+
+// ErrMicroPayReversed ...
+var ErrMicroPayReversed = errors.New("micropay is not paid and the order has been reversed")
+
+// ErrMicroPayReverseFailed ...
+var ErrMicroPayReverseFailed = errors.New("micropay reverse failed")
+
+// ErrInvalidRefundFee ...
+var ErrInvalidRefundFee = errors.New("refund fee must be greater than zero")
+
+// ErrRefundExceeded ...
+var ErrRefundExceeded = errors.New("refund fee exceeds the remaining amount of the order")
+
+// ErrRefundFeeMismatch ...
+var ErrRefundFeeMismatch = errors.New("out_refund_no is already recorded with a different refund fee")
+
+// ErrRefundLedgerConflict ...
+var ErrRefundLedgerConflict = errors.New("refund ledger was modified concurrently, please retry")
+
+// ErrNilPublicKey ...
+var ErrNilPublicKey = errors.New("nil rsa public key")
+
+// ErrRedPackRequired ...
+var ErrRedPackRequired = errors.New("mch_billno,send_name,re_openid,wishing,act_name,remark are required")
+
+// ErrRedPackAmount ...
+var ErrRedPackAmount = errors.New("total_amount must be greater than zero")
+
+// ErrRedPackSceneRequired ...
+var ErrRedPackSceneRequired = errors.New("scene_id is required when total_amount is less than 1 or greater than 200 yuan")
+
+// ErrRedPackTotalNum ...
+var ErrRedPackTotalNum = errors.New("total_num of group red pack must between 3 and 20")
+
+// ErrTransferAmount ...
+var ErrTransferAmount = errors.New("amount must be greater than zero")
+
+// ErrUnknownBank ...
+var ErrUnknownBank = errors.New("unknown bank, bank_code must be one of the bank code list")
+
+// ErrNilPlatformCert ...
+var ErrNilPlatformCert = errors.New("platform_cert_path is required to verify the notify signature")
+
+// ErrNotifySignatureRequired ...
+var ErrNotifySignatureRequired = errors.New("Wechatpay-Timestamp,Wechatpay-Nonce,Wechatpay-Signature headers are required")
+
+// ErrInvalidNotifySignature ...
+var ErrInvalidNotifySignature = errors.New("invalid notify signature")
+
+// ErrSubMerchantNotFound ...
+var ErrSubMerchantNotFound = errors.New("sub merchant not found")
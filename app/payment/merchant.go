@@ -21,19 +21,38 @@ func NewMerchant(config *core.Config) *Merchant {
 	return newMerchant(NewPayment(config)).(*Merchant)
 }
 
-// AddSubMerchant ...
+// AddSubMerchant 添加子商户,成功后注册到子商户注册表
 func (m *Merchant) AddSubMerchant(maps util.Map) core.Responder {
-	return m.manage("add", maps)
+	resp := m.manage("add", maps)
+	if rlt, b := m.succeed(resp); b {
+		m.subMerchants.Register(&SubMerchant{
+			MchID: rlt.GetString("sub_mch_id"),
+			AppID: maps.GetString("sub_appid"),
+			Name:  maps.GetString("merchant_name"),
+		})
+	}
+	return resp
 }
 
-// QuerySubMerchantByMerchantID ...
+// QuerySubMerchantByMerchantID 查询子商户,成功后更新子商户注册表
 func (m *Merchant) QuerySubMerchantByMerchantID(id string) core.Responder {
-	return m.manage("dummyQuery", util.Map{"micro_mch_id": id})
+	resp := m.manage("query", util.Map{"micro_mch_id": id})
+	if rlt, b := m.succeed(resp); b {
+		sub, has := m.subMerchants.Get(id)
+		if !has {
+			sub = &SubMerchant{MchID: id}
+		}
+		if name := rlt.GetString("merchant_name"); name != "" {
+			sub.Name = name
+		}
+		m.subMerchants.Register(sub)
+	}
+	return resp
 }
 
 // QuerySubMerchantByWeChatID ...
 func (m *Merchant) QuerySubMerchantByWeChatID(id string) core.Responder {
-	return m.manage("dummyQuery", util.Map{"recipient_wechatid": id})
+	return m.manage("query", util.Map{"recipient_wechatid": id})
 }
 
 // ModifyInfo ...
@@ -42,7 +61,7 @@ func (m *Merchant) ModifyInfo(maps util.Map) core.Responder {
 		"mch_id":     m.GetString("mch_id"),
 		"sub_mch_id": "",
 	})
-	return m.SafeRequest(mchModifymchinfo, maps)
+	return m.provider().SafeRequest(mchModifymchinfo, maps)
 }
 
 // AddRecommendConfBySubscribe ...
@@ -53,7 +72,7 @@ func (m *Merchant) AddRecommendConfBySubscribe(appID string) core.Responder {
 		"sub_mch_id":      "",
 		"sub_appid":       "",
 	}
-	return m.SafeRequest(mktAddrecommendconf, maps)
+	return m.provider().SafeRequest(mktAddrecommendconf, maps)
 }
 
 // AddRecommendConfByReceipt ...
@@ -64,11 +83,70 @@ func (m *Merchant) AddRecommendConfByReceipt(appID string) core.Responder {
 		"sub_mch_id":    "",
 		"sub_appid":     "",
 	}
-	return m.SafeRequest(mktAddrecommendconf, maps)
+	return m.provider().SafeRequest(mktAddrecommendconf, maps)
+}
+
+/*AddSubAppID 服务商为子商户绑定sub_appid,成功后更新子商户注册表
+接口链接:https://api.mch.weixin.qq.com/secapi/mch/addsubdevconfig
+*/
+func (m *Merchant) AddSubAppID(subMchID, subAppID string) core.Responder {
+	resp := m.addSubDevConfig(util.Map{
+		"sub_mch_id": subMchID,
+		"sub_appid":  subAppID,
+	})
+	if _, b := m.succeed(resp); b {
+		sub, has := m.subMerchants.Get(subMchID)
+		if !has {
+			sub = &SubMerchant{MchID: subMchID}
+		}
+		sub.AppID = subAppID
+		m.subMerchants.Register(sub)
+	}
+	return resp
+}
+
+/*AddJSAPIPath 服务商为子商户配置支付授权目录
+接口链接:https://api.mch.weixin.qq.com/secapi/mch/addsubdevconfig
+*/
+func (m *Merchant) AddJSAPIPath(subMchID, path string) core.Responder {
+	return m.addSubDevConfig(util.Map{
+		"sub_mch_id": subMchID,
+		"jsapi_path": path,
+	})
+}
+
+func (m *Merchant) addSubDevConfig(maps util.Map) core.Responder {
+	maps.Set("appid", m.GetString("app_id"))
+	maps.Set("mch_id", m.GetString("mch_id"))
+	maps.Set("sign", util.GenerateSignatureWithIgnore(maps, m.GetKey(), []string{util.FieldSign}))
+	return core.Request(core.POST, m.Link(mchAddSubDevConfig), util.Map{
+		core.DataTypeXML:      maps,
+		core.DataTypeSecurity: m.Config,
+	})
+}
+
+func (m *Merchant) succeed(resp core.Responder) (util.Map, bool) {
+	rlt, err := resp.Result()
+	if err != nil || rlt.GetString("return_code") != "SUCCESS" ||
+		rlt.GetString("result_code") != "SUCCESS" {
+		return rlt, false
+	}
+	return rlt, true
 }
 
-func (m *Merchant) mchAddSubDevConfig() {
-	//TODO
+/*provider 商户管理接口以服务商身份请求
+在WithSubMerchant/OnBehalfOf返回的Payment上调用时,不注入当前子商户的sub_mch_id/sub_appid
+*/
+func (m *Merchant) provider() *Payment {
+	if m.subMerchant == nil {
+		return m.Payment
+	}
+	return &Payment{
+		Config:       m.Config,
+		Module:       util.Map{},
+		prefix:       m.prefix,
+		subMerchants: m.subMerchants,
+	}
 }
 
 func (m *Merchant) manage(action string, maps util.Map) core.Responder {
@@ -80,9 +158,9 @@ func (m *Merchant) manage(action string, maps util.Map) core.Responder {
 		"sub_appid":  "",
 	})
 	params := util.Map{
-		core.DataTypeXML:      m.initRequest(maps),
+		core.DataTypeXML:      m.provider().initRequest(maps),
 		core.DataTypeQuery:    util.Map{"action": action},
 		core.DataTypeSecurity: m.Config,
 	}
-	return core.Request(Link(mchSubmchmanage), core.POST, params)
+	return core.Request(core.POST, Link(mchSubmchmanage), params)
 }
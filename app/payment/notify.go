@@ -1,11 +1,17 @@
 package payment
 
 import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/xml"
 	"github.com/godcong/wego/cipher"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/log"
 	"github.com/godcong/wego/util"
+	"io/ioutil"
 	"net/http"
 	"strings"
 )
@@ -162,6 +168,152 @@ func (n *paidNotify) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 }
 
+// ProfitSharingNotifyReceiver 分账动账通知中的接收方
+type ProfitSharingNotifyReceiver struct {
+	Type        string `json:"type"`
+	Account     string `json:"account"`
+	Amount      int    `json:"amount"`
+	Description string `json:"description"`
+}
+
+// ProfitSharingNotification 分账动账通知
+type ProfitSharingNotification struct {
+	ID            string                      `json:"id"`
+	CreateTime    string                      `json:"create_time"`
+	EventType     string                      `json:"event_type"`
+	Summary       string                      `json:"summary"`
+	MchID         string                      `json:"mchid"`
+	SpMchID       string                      `json:"sp_mchid"`
+	SubMchID      string                      `json:"sub_mchid"`
+	TransactionID string                      `json:"transaction_id"`
+	OrderID       string                      `json:"order_id"`
+	OutOrderNo    string                      `json:"out_order_no"`
+	Receiver      ProfitSharingNotifyReceiver `json:"receiver"`
+	SuccessTime   string                      `json:"success_time"`
+}
+
+type notifyResource struct {
+	Algorithm      string `json:"algorithm"`
+	Ciphertext     string `json:"ciphertext"`
+	AssociatedData string `json:"associated_data"`
+	Nonce          string `json:"nonce"`
+}
+
+type notifyEnvelope struct {
+	ID           string         `json:"id"`
+	CreateTime   string         `json:"create_time"`
+	EventType    string         `json:"event_type"`
+	ResourceType string         `json:"resource_type"`
+	Summary      string         `json:"summary"`
+	Resource     notifyResource `json:"resource"`
+}
+
+// ProfitSharingNotifyCallback ...
+type ProfitSharingNotifyCallback func(n *ProfitSharingNotification) error
+
+/*Notify 监听 */
+type profitSharingNotify struct {
+	*Payment
+	ProfitSharingNotifyCallback
+}
+
+// DecodeProfitSharingNotify 解密分账动账通知,key为APIv3密钥
+func DecodeProfitSharingNotify(key string, body []byte) (*ProfitSharingNotification, error) {
+	var envelope notifyEnvelope
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		return nil, err
+	}
+	r := envelope.Resource
+	dec, err := cipher.AEADAES256GCMDecrypt([]byte(key), []byte(r.Nonce), []byte(r.AssociatedData), []byte(r.Ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	n := &ProfitSharingNotification{}
+	err = json.Unmarshal(dec, n)
+	if err != nil {
+		return nil, err
+	}
+	n.ID = envelope.ID
+	n.CreateTime = envelope.CreateTime
+	n.EventType = envelope.EventType
+	n.Summary = envelope.Summary
+	return n, nil
+}
+
+/*VerifyNotifySignature 使用微信支付平台证书验证APIv3通知签名
+签名串为 Wechatpay-Timestamp\nWechatpay-Nonce\n报文主体\n,cert为PEM格式的平台证书或公钥
+*/
+func VerifyNotifySignature(cert []byte, header http.Header, body []byte) error {
+	timestamp := header.Get("Wechatpay-Timestamp")
+	nonce := header.Get("Wechatpay-Nonce")
+	signature := header.Get("Wechatpay-Signature")
+	if timestamp == "" || nonce == "" || signature == "" {
+		return ErrNotifySignatureRequired
+	}
+	key, err := cipher.ParseRSAPublicKeyFromPEM(cert)
+	if err != nil {
+		return err
+	}
+	sign, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidNotifySignature
+	}
+	hashed := sha256.Sum256([]byte(timestamp + "\n" + nonce + "\n" + string(body) + "\n"))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], sign) != nil {
+		return ErrInvalidNotifySignature
+	}
+	return nil
+}
+
+//verify 读取platform_cert_path配置的平台证书验证通知签名,未配置证书时拒绝通知
+func (n *profitSharingNotify) verify(header http.Header, body []byte) error {
+	path := n.GetString("platform_cert_path")
+	if path == "" {
+		return ErrNilPlatformCert
+	}
+	cert, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return VerifyNotifySignature(cert, header, body)
+}
+
+// ServeHTTP ...
+func (n *profitSharingNotify) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rlt := util.Map{"code": "SUCCESS", "message": "成功"}
+	status := http.StatusOK
+	defer func() {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		_, err := w.Write(rlt.ToJSON())
+		if err != nil {
+			log.Error(err)
+		}
+	}()
+
+	body, err := core.ParseRequest(req)
+	if err == nil {
+		err = n.verify(req.Header, body)
+	}
+	if err == nil {
+		var notification *ProfitSharingNotification
+		notification, err = DecodeProfitSharingNotify(n.GetString("api_v3_key"), body)
+		if err == nil {
+			if n.ProfitSharingNotifyCallback == nil {
+				log.Error(ErrNilNotifyCallback)
+				return
+			}
+			err = n.ProfitSharingNotifyCallback(notification)
+		}
+	}
+	if err != nil {
+		log.Error(err)
+		status = http.StatusInternalServerError
+		rlt = util.Map{"code": "FAIL", "message": err.Error()}
+	}
+}
+
 // NotifyResponseXML ...
 func NotifyResponseXML(w http.ResponseWriter, data []byte) error {
 	w.WriteHeader(http.StatusOK)
@@ -12,32 +12,36 @@ import (
 //Payment ...
 type Payment struct {
 	*core.Config
-	Module util.Map
-	prefix string
+	Module       util.Map
+	prefix       string
+	subMerchant  *SubMerchant
+	subMerchants *SubMerchants
 }
 
 //NewPaymentAble ...
 type NewPaymentAble func(payment *Payment) interface{}
 
 var moduleLists = util.Map{
-	"Bill":     newBill,
-	"Coupon":   newCoupon,
-	"JSSDK":    newJSSDK,
-	"Merchant": newMerchant,
-	"Order":    newOrder,
-	"RedPack":  newRedPack,
-	"Refund":   newRefund,
-	"Reverse":  newReverse,
-	"Sandbox":  newSandbox,
-	"Security": newSecurity,
-	"Transfer": newTransfer,
+	"Bill":          newBill,
+	"Coupon":        newCoupon,
+	"JSSDK":         newJSSDK,
+	"Merchant":      newMerchant,
+	"Order":         newOrder,
+	"ProfitSharing": newProfitSharing,
+	"RedPack":       newRedPack,
+	"Refund":        newRefund,
+	"Reverse":       newReverse,
+	"Sandbox":       newSandbox,
+	"Security":      newSecurity,
+	"Transfer":      newTransfer,
 }
 
 func newPayment(config *core.Config, p util.Map) *Payment {
 	payment := &Payment{
-		Config: config,
-		Module: p,
-		prefix: "",
+		Config:       config,
+		Module:       p,
+		prefix:       "",
+		subMerchants: NewSubMerchants(),
 	}
 
 	return payment
@@ -110,6 +114,7 @@ func (p *Payment) Scheme(pid string) string {
 }
 
 //SetSubMerchant set Module merchat
+//Deprecated: SetSubMerchant changes the shared config, use WithSubMerchant instead
 func (p *Payment) SetSubMerchant(mchID, appID string) *Payment {
 	p.Set("sub_mch_id", mchID)
 	p.Set("sub_appid", appID)
@@ -238,6 +243,26 @@ func (p *Payment) Coupon() *Coupon {
 	return obj.(*Coupon)
 }
 
+// ProfitSharing ...
+func (p *Payment) ProfitSharing() *ProfitSharing {
+	obj, b := p.Module["ProfitSharing"]
+	if !b {
+		obj = newProfitSharing(p)
+		//p.Module["ProfitSharing"] = obj
+	}
+	return obj.(*ProfitSharing)
+}
+
+// Merchant ...
+func (p *Payment) Merchant() *Merchant {
+	obj, b := p.Module["Merchant"]
+	if !b {
+		obj = newMerchant(p)
+		//p.Module["Merchant"] = obj
+	}
+	return obj.(*Merchant)
+}
+
 // HandleRefundedNotify ...
 func (p *Payment) HandleRefundedNotify(f NotifyCallback) Notify {
 	return &refundedNotify{
@@ -277,6 +302,19 @@ func (p *Payment) HandlePaid(f NotifyCallback) NotifyFunc {
 	return p.HandlePaidNotify(f).ServeHTTP
 }
 
+// HandleProfitSharingNotify 分账动账通知,需要配置api_v3_key和微信支付平台证书platform_cert_path
+func (p *Payment) HandleProfitSharingNotify(f ProfitSharingNotifyCallback) Notify {
+	return &profitSharingNotify{
+		Payment:                     p,
+		ProfitSharingNotifyCallback: f,
+	}
+}
+
+// HandleProfitSharing ...
+func (p *Payment) HandleProfitSharing(f ProfitSharingNotifyCallback) NotifyFunc {
+	return p.HandleProfitSharingNotify(f).ServeHTTP
+}
+
 func (p *Payment) initRequestWithIgnore(maps util.Map, ignore ...string) util.Map {
 	if p == nil || maps == nil {
 		return nil
@@ -284,12 +322,7 @@ func (p *Payment) initRequestWithIgnore(maps util.Map, ignore ...string) util.Ma
 
 	maps.Set("mch_id", p.GetString("mch_id"))
 	maps.Set("nonce_str", util.GenerateUUID())
-	if p.Has("sub_mch_id") {
-		maps.Set("sub_mch_id", p.GetString("sub_mch_id"))
-	}
-	if p.Has("sub_appid") {
-		maps.Set("sub_appid", p.GetString("sub_appid"))
-	}
+	p.setSubMerchant(maps)
 
 	if !maps.Has("sign") {
 		maps.Set("sign", util.GenerateSignatureWithIgnore(maps, p.GetKey(), ignore))
@@ -299,18 +332,29 @@ func (p *Payment) initRequestWithIgnore(maps util.Map, ignore ...string) util.Ma
 	return maps
 }
 
-func (p *Payment) initRequest(maps util.Map) util.Map {
-	if p == nil || maps == nil {
-		return nil
+func (p *Payment) setSubMerchant(maps util.Map) {
+	if p.subMerchant != nil {
+		maps.Set("sub_mch_id", p.subMerchant.MchID)
+		if p.subMerchant.AppID != "" {
+			maps.Set("sub_appid", p.subMerchant.AppID)
+		}
+		return
 	}
-	maps.Set("mch_id", p.GetString("mch_id"))
-	maps.Set("nonce_str", util.GenerateUUID())
 	if p.Has("sub_mch_id") {
 		maps.Set("sub_mch_id", p.GetString("sub_mch_id"))
 	}
 	if p.Has("sub_appid") {
 		maps.Set("sub_appid", p.GetString("sub_appid"))
 	}
+}
+
+func (p *Payment) initRequest(maps util.Map) util.Map {
+	if p == nil || maps == nil {
+		return nil
+	}
+	maps.Set("mch_id", p.GetString("mch_id"))
+	maps.Set("nonce_str", util.GenerateUUID())
+	p.setSubMerchant(maps)
 
 	if !maps.Has("sign") {
 		maps.Set("sign", util.GenerateSignatureWithIgnore(maps, p.GetKey(), []string{util.FieldSign}))
@@ -1,15 +1,31 @@
 package payment_test
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"errors"
 	"github.com/godcong/wego"
 	"github.com/godcong/wego/app/payment"
+	"github.com/godcong/wego/cache"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/log"
 	"github.com/godcong/wego/util"
 	"golang.org/x/text/encoding/simplifiedchinese"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 var outTradeNo = "201813091059590000003433-asd003"
@@ -94,6 +110,101 @@ func TestRefund_ByOutTradeNumber(t *testing.T) {
 	t.Log(resp.ToMap())
 }
 
+// TestRefundLedger_ByOutTradeNumber ...
+func TestRefundLedger_ByOutTradeNumber(t *testing.T) {
+	refunds := util.Map{}
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		if path != "/secapi/pay/refund" {
+			return nil
+		}
+		no := req.GetString("out_refund_no")
+		n, _ := refunds.GetInt64(no)
+		refunds.Set(no, n+1)
+		if no == "R1" && n == 0 {
+			return util.Map{"return_code": "SUCCESS", "result_code": "FAIL", "err_code": "SYSTEMERROR"}
+		}
+		if no == "R3" {
+			return util.Map{"return_code": "SUCCESS", "result_code": "FAIL", "err_code": "NOTENOUGH"}
+		}
+		return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS"}
+	})
+	ledger := payment.NewRefundLedger(cfg, nil).SetAccount(payment.RefundAccountRecharge)
+	refunded := func(want int) {
+		t.Helper()
+		if n, err := ledger.Refunded("T1001"); err != nil || n != want {
+			t.Fatal(n, err)
+		}
+	}
+
+	//SYSTEMERROR保持PENDING并占用金额
+	ledger.ByOutTradeNumber("T1001", "R1", 100, 60)
+	refunded(60)
+	if _, err := ledger.ByOutTradeNumber("T1001", "R2", 100, 50).Result(); err != payment.ErrRefundExceeded {
+		t.Fatal(err)
+	}
+
+	//相同out_refund_no重试不重复计算
+	if rlt, _ := ledger.ByOutTradeNumber("T1001", "R1", 100, 60).Result(); rlt.GetString("result_code") != "SUCCESS" {
+		t.Fatal(rlt)
+	}
+	refunded(60)
+	if entries, _ := ledger.Entries("T1001"); entries["R1"].Status != payment.RefundEntryConfirmed {
+		t.Error(entries["R1"])
+	}
+	if _, err := ledger.ByOutTradeNumber("T1001", "R1", 100, 30).Result(); err != payment.ErrRefundFeeMismatch {
+		t.Error(err)
+	}
+
+	//明确失败的退款不占用金额
+	ledger.ByOutTradeNumber("T1001", "R3", 100, 40)
+	refunded(60)
+	ledger.ByOutTradeNumber("T1001", "R4", 100, 40)
+	refunded(100)
+	if n, _ := refunds.GetInt64("R2"); n != 0 {
+		t.Error("exceeded refund should not be sent")
+	}
+}
+
+// TestRefundLedger_Reconcile ...
+func TestRefundLedger_Reconcile(t *testing.T) {
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		switch path {
+		case "/secapi/pay/refund":
+			return nil
+		case "/pay/refundquery":
+			return util.Map{
+				"return_code":        "SUCCESS",
+				"result_code":        "SUCCESS",
+				"total_refund_count": "2",
+				"refund_count":       "2",
+				"out_refund_no_0":    "R1",
+				"refund_fee_0":       "30",
+				"refund_status_0":    "SUCCESS",
+				"out_refund_no_1":    "R9",
+				"refund_fee_1":       "20",
+				"refund_status_1":    "REFUNDCLOSE",
+			}
+		}
+		return nil
+	})
+	ledger := payment.NewRefundLedger(cfg, nil)
+	//请求失败,R1,R2均为PENDING
+	ledger.ByOutTradeNumber("T1002", "R1", 100, 30)
+	ledger.ByOutTradeNumber("T1002", "R2", 100, 50)
+	if n, _ := ledger.Refunded("T1002"); n != 80 {
+		t.Fatal(n)
+	}
+
+	n, err := ledger.Reconcile("T1002")
+	if err != nil || n != 30 {
+		t.Fatal(n, err)
+	}
+	entries, _ := ledger.Entries("T1002")
+	if len(entries) != 1 || entries["R1"].Status != payment.RefundEntryConfirmed {
+		t.Error(entries)
+	}
+}
+
 // TestOrder_Unify ...
 func TestOrder_Unify(t *testing.T) {
 	m := make(util.Map)
@@ -178,32 +289,104 @@ func TestValidateSign(t *testing.T) {
 
 // TestMerchant_AddSubMerchant ...
 func TestMerchant_AddSubMerchant(t *testing.T) {
-	obj := payment.NewMerchant(cfg)
-	resp := obj.AddSubMerchant(util.Map{
-		"page_index": 1,
-		"page_size":  10,
+	var got util.Map
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		got = req
+		return util.Map{
+			"return_code": "SUCCESS",
+			"result_code": "SUCCESS",
+			"sub_mch_id":  "sub-mch-a",
+		}
 	})
-
-	t.Log(resp.Error())
-	t.Log(resp.ToMap())
+	pay := payment.NewPayment(cfg)
+	resp := pay.Merchant().AddSubMerchant(util.Map{
+		"merchant_name": "测试商户",
+		"sub_appid":     "wx8888888888888888",
+	})
+	if resp.Error() != nil {
+		t.Fatal(resp.Error())
+	}
+	if got.GetString("sub_mch_id") != "" {
+		t.Error(got)
+	}
+	sub, b := pay.SubMerchants().Get("sub-mch-a")
+	if !b || sub.AppID != "wx8888888888888888" || sub.Name != "测试商户" {
+		t.Error(sub)
+	}
 }
 
 // TestMerchant_QuerySubMerchantByMerchantId ...
 func TestMerchant_QuerySubMerchantByMerchantId(t *testing.T) {
-	obj := payment.NewMerchant(cfg)
-	resp := obj.QuerySubMerchantByMerchantID("123")
-
-	t.Log(resp.Error())
-	t.Log(resp.ToMap())
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		return util.Map{
+			"return_code":   "SUCCESS",
+			"result_code":   "SUCCESS",
+			"merchant_name": "测试商户",
+		}
+	})
+	pay := payment.NewPayment(cfg)
+	resp := pay.Merchant().QuerySubMerchantByMerchantID("sub-mch-a")
+	if resp.Error() != nil {
+		t.Fatal(resp.Error())
+	}
+	sub, b := pay.SubMerchants().Get("sub-mch-a")
+	if !b || sub.Name != "测试商户" {
+		t.Error(sub)
+	}
 }
 
 // TestMerchant_QuerySubMerchantByWeChatId ...
 func TestMerchant_QuerySubMerchantByWeChatId(t *testing.T) {
-	obj := payment.NewMerchant(cfg)
-	resp := obj.QuerySubMerchantByWeChatID("123")
+	var got util.Map
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		got = req
+		return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS"}
+	})
+	resp := payment.NewMerchant(cfg).QuerySubMerchantByWeChatID("wechat-a")
+	if resp.Error() != nil {
+		t.Fatal(resp.Error())
+	}
+	if got.GetString("recipient_wechatid") != "wechat-a" {
+		t.Error(got)
+	}
+}
 
-	t.Log(resp.Error())
-	t.Log(resp.ToMap())
+// TestPayment_WithSubMerchant ...
+func TestPayment_WithSubMerchant(t *testing.T) {
+	reqs := map[string]util.Map{}
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		reqs[path] = req
+		return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS"}
+	})
+	pay := payment.NewPayment(cfg)
+	pay.SubMerchants().Register(&payment.SubMerchant{
+		MchID: "sub-mch-a",
+		AppID: "wx8888888888888888",
+	})
+
+	sub, err := pay.OnBehalfOf("sub-mch-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pay.SubMerchant() != nil || sub.SubMerchant().AppID != "wx8888888888888888" {
+		t.Error(sub.SubMerchant())
+	}
+	if _, err := pay.OnBehalfOf("sub-mch-b"); err != payment.ErrSubMerchantNotFound {
+		t.Error(err)
+	}
+
+	sub.Order().QueryByOutTradeNumber("trade-a")
+	order := reqs["/pay/orderquery"]
+	if order.GetString("sub_mch_id") != "sub-mch-a" || order.GetString("sub_appid") != "wx8888888888888888" {
+		t.Error(order)
+	}
+
+	// 商户管理接口以服务商身份请求,不注入子商户上下文
+	sub.Merchant().QuerySubMerchantByWeChatID("wechat-a")
+	manage := reqs["/secapi/mch/submchmanage"]
+	if manage.GetString("sub_mch_id") != "" || manage.GetString("sub_appid") != "" {
+		t.Error(manage)
+	}
 }
 
 // TestBill_Download ...
@@ -237,7 +420,7 @@ func TestBill_DownloadFundFlow(t *testing.T) {
 
 // TestBase_Pay ...
 func TestBase_Pay(t *testing.T) {
-	base := payment.NewBase(cfg)
+	base := payment.NewPayment(cfg)
 	resp := base.Pay(util.Map{
 		"body":         "image形象店-深圳腾大- QQ公仔",
 		"out_trade_no": "1217752501201407033233368018",
@@ -248,9 +431,139 @@ func TestBase_Pay(t *testing.T) {
 	t.Log(resp.ToMap())
 }
 
+// mockHandler 模拟支付接口,path为接口地址,req为请求参数,返回nil时响应500
+type mockHandler func(path string, req util.Map) util.Map
+
+// mockPayment 启动模拟支付服务,返回指向该服务的Payment配置,测试结束后恢复默认配置
+func mockPayment(t *testing.T, handler mockHandler) *core.Config {
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		rlt := handler(r.URL.Path, util.XMLToMap(body))
+		mu.Unlock()
+		if rlt == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write(rlt.ToXML())
+	}))
+	old := cache.Get("config")
+	cache.Set("config", wego.C(util.Map{
+		"domain": util.Map{
+			"payment": util.Map{"url": srv.URL},
+		},
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		cache.Set("config", old)
+	})
+	return wego.C(util.Map{
+		"app_id": "wx2421b1c4370ec43b",
+		"mch_id": "10000100",
+		"key":    "aTKnSUcTkbEnhwQNdutWkQxAjnhAz2jK",
+	})
+}
+
+// mockPolicy 测试用的刷卡支付策略
+var mockPolicy = &payment.MicroPayPolicy{
+	QueryInterval:     time.Millisecond,
+	QueryTimeout:      20 * time.Millisecond,
+	ReverseBackoff:    time.Millisecond,
+	ReverseMaxBackoff: 2 * time.Millisecond,
+	ReverseLimit:      3,
+}
+
+var microPayParams = util.Map{
+	"body":         "image形象店-深圳腾大- QQ公仔",
+	"out_trade_no": "1217752501201407033233368019",
+	"total_fee":    "888",
+	"auth_code":    "120061098828009406",
+}
+
+// TestPayment_PayByAuthCode ...
+func TestPayment_PayByAuthCode(t *testing.T) {
+	var queried, reversed int
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		switch path {
+		case "/pay/micropay":
+			return util.Map{"return_code": "SUCCESS", "result_code": "FAIL", "err_code": "USERPAYING"}
+		case "/pay/orderquery":
+			if queried++; queried < 2 {
+				return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS", "trade_state": "USERPAYING"}
+			}
+			return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS", "trade_state": "SUCCESS"}
+		case "/secapi/pay/reverse":
+			reversed++
+		}
+		return nil
+	})
+	policy := *mockPolicy
+	policy.QueryTimeout = time.Second
+	rlt, err := payment.NewPayment(cfg).PayByAuthCodeWithPolicy(microPayParams, &policy)
+	if err != nil || rlt.GetString("trade_state") != "SUCCESS" {
+		t.Fatal(rlt, err)
+	}
+	if queried != 2 || reversed != 0 {
+		t.Error(queried, reversed)
+	}
+}
+
+// TestPayment_PayByAuthCode_CommunicationFailure ...
+func TestPayment_PayByAuthCode_CommunicationFailure(t *testing.T) {
+	var queried, reversed int
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		switch path {
+		case "/pay/micropay":
+			return util.Map{"return_code": "FAIL", "return_msg": "SYSTEMERROR"}
+		case "/pay/orderquery":
+			queried++
+			return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS", "trade_state": "USERPAYING"}
+		case "/secapi/pay/reverse":
+			if reversed++; reversed == 1 {
+				return nil
+			}
+			if reversed == 2 {
+				return util.Map{"return_code": "SUCCESS", "result_code": "FAIL", "err_code": "SYSTEMERROR", "recall": "Y"}
+			}
+			return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS", "recall": "N"}
+		}
+		return nil
+	})
+	_, err := payment.NewPayment(cfg).PayByAuthCodeWithPolicy(microPayParams, mockPolicy)
+	if err != payment.ErrMicroPayReversed {
+		t.Fatal(err)
+	}
+	if queried == 0 || reversed != 3 {
+		t.Error(queried, reversed)
+	}
+}
+
+// TestPayment_PayByAuthCode_NoRecall ...
+func TestPayment_PayByAuthCode_NoRecall(t *testing.T) {
+	var reversed int
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		switch path {
+		case "/pay/micropay":
+			return util.Map{"return_code": "SUCCESS", "result_code": "FAIL", "err_code": "SYSTEMERROR"}
+		case "/pay/orderquery":
+			return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS", "trade_state": "PAYERROR"}
+		case "/secapi/pay/reverse":
+			reversed++
+			return util.Map{"return_code": "SUCCESS", "result_code": "FAIL", "err_code": "REVERSE_EXPIRE", "recall": "N"}
+		}
+		return nil
+	})
+	_, err := payment.NewPayment(cfg).PayByAuthCodeWithPolicy(microPayParams, mockPolicy)
+	if err != payment.ErrMicroPayReverseFailed || reversed != 1 {
+		t.Fatal(err, reversed)
+	}
+}
+
 // TestBase_AuthCodeToOpenid ...
 func TestBase_AuthCodeToOpenid(t *testing.T) {
-	base := payment.NewBase(cfg)
+	base := payment.NewPayment(cfg)
 	resp := base.AuthCodeToOpenid("1212121")
 	t.Log(resp.Error())
 	t.Log(resp.ToMap())
@@ -273,6 +586,126 @@ func TestNewTransfer(t *testing.T) {
 	t.Log(m1.ToMap())
 }
 
+// mockPublicKey 生成RSA公钥文件,返回文件路径
+func mockPublicKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "publickey.pem")
+	pub := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+	if err := ioutil.WriteFile(path, pub, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestTransfer_ToBankCardRequest ...
+func TestTransfer_ToBankCardRequest(t *testing.T) {
+	var req util.Map
+	cfg := mockPayment(t, func(path string, r util.Map) util.Map {
+		if path != "/mmpaysptrans/pay_bank" {
+			return nil
+		}
+		req = r
+		return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS", "payment_no": "P10000"}
+	})
+	cfg.Set("pubkey_path", mockPublicKey(t))
+
+	rlt, err := payment.NewTransfer(cfg).ToBankCardRequest(&payment.BankCardRequest{
+		PartnerTradeNo: "12345678",
+		BankNo:         "6217001210053551022",
+		TrueName:       "蒋聪聪",
+		BankName:       "建设银行",
+		Amount:         1000,
+	}).Result()
+	if err != nil || rlt.GetString("payment_no") != "P10000" {
+		t.Fatal(rlt, err)
+	}
+	if req.GetString("enc_true_name") == "" || req.GetString("enc_true_name") == "蒋聪聪" {
+		t.Error("enc_true_name should be encrypted", req)
+	}
+}
+
+// TestTransfer_ToBankCard_Retry ...
+func TestTransfer_ToBankCard_Retry(t *testing.T) {
+	var requested int
+	cfg := mockPayment(t, func(path string, r util.Map) util.Map {
+		requested++
+		return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS", "payment_no": "P10000"}
+	})
+	cfg.Set("pubkey_path", mockPublicKey(t))
+
+	m := util.Map{
+		"partner_trade_no": "12345678",
+		"enc_bank_no":      "6217001210053551022",
+		"enc_true_name":    "蒋聪聪",
+		"bank_code":        "1003",
+		"amount":           "1000",
+	}
+	tran := payment.NewTransfer(cfg)
+	for i := 0; i < 2; i++ {
+		rlt, err := tran.ToBankCard(m).Result()
+		if err != nil || rlt.GetString("payment_no") != "P10000" {
+			t.Fatal(rlt, err)
+		}
+		if m.GetString("enc_bank_no") != "6217001210053551022" || m.GetString("enc_true_name") != "蒋聪聪" || m.Has("mch_id") {
+			t.Fatal("input map should be unchanged", m)
+		}
+	}
+	if requested != 2 {
+		t.Error(requested)
+	}
+}
+
+// TestTransfer_ToBankCard_UnknownBank ...
+func TestTransfer_ToBankCard_UnknownBank(t *testing.T) {
+	requested := false
+	cfg := mockPayment(t, func(path string, r util.Map) util.Map {
+		requested = true
+		return nil
+	})
+	cfg.Set("pubkey_path", mockPublicKey(t))
+
+	resp := payment.NewTransfer(cfg).ToBankCard(util.Map{
+		"partner_trade_no": "12345678",
+		"enc_bank_no":      "6217001210053551022",
+		"enc_true_name":    "蒋聪聪",
+		"bank_code":        "9999",
+		"amount":           "1000",
+	})
+	if resp.Error() != payment.ErrUnknownBank || requested {
+		t.Error(resp.Error(), requested)
+	}
+	_, err := payment.NewTransfer(cfg).ToBankCardRequest(&payment.BankCardRequest{
+		PartnerTradeNo: "12345678",
+		BankName:       "不存在的银行",
+		Amount:         1000,
+	}).Result()
+	if err != payment.ErrUnknownBank {
+		t.Error(err)
+	}
+}
+
+// TestRedPack_InfoResult ...
+func TestRedPack_InfoResult(t *testing.T) {
+	cfg := mockPayment(t, func(path string, r util.Map) util.Map {
+		if path != "/mmpaymkttransfers/gethbinfo" {
+			return nil
+		}
+		return util.Map{
+			"return_code": "SUCCESS",
+			"result_code": "SUCCESS",
+			"status":      "RECEIVED",
+			"hb_type":     "NORMAL",
+		}
+	})
+	info, err := payment.NewRedPack(cfg).InfoResult("RP201411111234567890")
+	if err != nil || info.Status != "RECEIVED" || info.HbType != "NORMAL" {
+		t.Fatal(info, err)
+	}
+}
+
 // TestCoupon_Send ...
 func TestCoupon_Send(t *testing.T) {
 	resp := payment.NewCoupon(cfg).Send(util.Map{
@@ -337,6 +770,111 @@ func TestScannedNotify_ServeHTTP(t *testing.T) {
 	t.Fatal(http.ListenAndServe(":8080", nil))
 }
 
+// TestProfitSharing_Share ...
+func TestProfitSharing_Share(t *testing.T) {
+	reqs := map[string]util.Map{}
+	cfg := mockPayment(t, func(path string, req util.Map) util.Map {
+		reqs[path] = req
+		return util.Map{"return_code": "SUCCESS", "result_code": "SUCCESS"}
+	})
+	ps := payment.NewProfitSharing(cfg)
+	receiver := &payment.ProfitSharingReceiver{
+		Type:         payment.ReceiverTypeMerchantID,
+		Account:      "190001001",
+		Name:         "示例商户全称",
+		RelationType: "SERVICE_PROVIDER",
+	}
+	if resp := ps.AddReceiver(receiver); resp.Error() != nil {
+		t.Fatal(resp.Error())
+	}
+	ps.Share("4208450740201411110007820472", "P20150806125346", []*payment.ProfitSharingReceiver{
+		{Type: payment.ReceiverTypeMerchantID, Account: "190001001", Amount: 100, Description: "分到商户"},
+	})
+	ps.Finish("4208450740201411110007820472", "P20150806125347", 888, "分账已完成")
+
+	for path, req := range reqs {
+		if req.GetString("sign_type") != util.HMACSHA256 || req.GetString("sign") == "" {
+			t.Error(path, req)
+		}
+	}
+	if _, b := reqs["/pay/profitsharingaddreceiver"]; !b {
+		t.Error(reqs)
+	}
+	if _, b := reqs["/secapi/pay/profitsharing"]; !b {
+		t.Error(reqs)
+	}
+	if amount, _ := reqs["/secapi/pay/profitsharingfinish"].GetInt64("amount"); amount != 888 {
+		t.Error(reqs["/secapi/pay/profitsharingfinish"])
+	}
+}
+
+// TestProfitSharingNotify_ServeHTTP ...
+func TestProfitSharingNotify_ServeHTTP(t *testing.T) {
+	v3Key := "0123456789abcdef0123456789abcdef"
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(t.TempDir(), "platform_cert.pem")
+	err = ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := aes.NewCipher([]byte(v3Key))
+	gcm, _ := gocipher.NewGCM(block)
+	nonce := "0123456789ab"
+	plain := `{"mchid":"1900000100","transaction_id":"4200000000000000","out_order_no":"P20150806125346","receiver":{"type":"MERCHANT_ID","account":"1900000109","amount":888}}`
+	ciphertext := base64.StdEncoding.EncodeToString(gcm.Seal(nil, []byte(nonce), []byte(plain), []byte("transaction")))
+	body := []byte(`{"id":"EV-0001","event_type":"TRANSACTION.SUCCESS","resource":{"algorithm":"AEAD_AES_256_GCM","ciphertext":"` +
+		ciphertext + `","associated_data":"transaction","nonce":"` + nonce + `"}}`)
+
+	sign := func(body []byte) string {
+		hashed := sha256.Sum256([]byte("1554208460\nnonce-a\n" + string(body) + "\n"))
+		b, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.StdEncoding.EncodeToString(b)
+	}
+
+	var received *payment.ProfitSharingNotification
+	pay := payment.NewPayment(wego.C(util.Map{
+		"app_id":             "wx2421b1c4370ec43b",
+		"mch_id":             "10000100",
+		"api_v3_key":         v3Key,
+		"platform_cert_path": certPath,
+	}))
+	handler := pay.HandleProfitSharing(func(n *payment.ProfitSharingNotification) error {
+		received = n
+		return nil
+	})
+	serve := func(signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/notify", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Wechatpay-Timestamp", "1554208460")
+		req.Header.Set("Wechatpay-Nonce", "nonce-a")
+		req.Header.Set("Wechatpay-Signature", signature)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Code
+	}
+
+	if code := serve(sign([]byte("{}"))); code != http.StatusInternalServerError || received != nil {
+		t.Fatal(code, received)
+	}
+	if code := serve(sign(body)); code != http.StatusOK || received == nil {
+		t.Fatal(code)
+	}
+	if received.OutOrderNo != "P20150806125346" || received.Receiver.Amount != 888 {
+		t.Error(received)
+	}
+}
+
 // TestJSSDK_GetTicket ...
 func TestJSSDK_GetTicket(t *testing.T) {
 	jssdk := payment.NewJSSDK(wego.C(util.Map{
@@ -0,0 +1,168 @@
+package payment
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/util"
+)
+
+/*分账接收方类型 */
+const (
+	ReceiverTypeMerchantID        = "MERCHANT_ID"
+	ReceiverTypePersonalWechatID  = "PERSONAL_WECHATID"
+	ReceiverTypePersonalOpenID    = "PERSONAL_OPENID"
+	ReceiverTypePersonalSubOpenID = "PERSONAL_SUB_OPENID"
+)
+
+/*ProfitSharingReceiver 分账接收方
+添加接收方时需要Name,RelationType;分账时需要Amount,Description
+*/
+type ProfitSharingReceiver struct {
+	Type           string `json:"type"`
+	Account        string `json:"account"`
+	Amount         int    `json:"amount,omitempty"`
+	Description    string `json:"description,omitempty"`
+	Name           string `json:"name,omitempty"`
+	RelationType   string `json:"relation_type,omitempty"`
+	CustomRelation string `json:"custom_relation,omitempty"`
+}
+
+/*ProfitSharing 分账 */
+type ProfitSharing struct {
+	*Payment
+}
+
+func newProfitSharing(p *Payment) interface{} {
+	return &ProfitSharing{
+		Payment: p,
+	}
+}
+
+/*NewProfitSharing NewProfitSharing */
+func NewProfitSharing(config *core.Config) *ProfitSharing {
+	return newProfitSharing(NewPayment(config)).(*ProfitSharing)
+}
+
+/*AddReceiver 添加分账接收方
+接口链接:https://api.mch.weixin.qq.com/pay/profitsharingaddreceiver
+*/
+func (s *ProfitSharing) AddReceiver(receiver *ProfitSharingReceiver) core.Responder {
+	return s.receiver(profitSharingAddReceiver, receiver)
+}
+
+/*RemoveReceiver 删除分账接收方
+接口链接:https://api.mch.weixin.qq.com/pay/profitsharingremovereceiver
+*/
+func (s *ProfitSharing) RemoveReceiver(receiver *ProfitSharingReceiver) core.Responder {
+	return s.receiver(profitSharingRemoveReceiver, &ProfitSharingReceiver{
+		Type:    receiver.Type,
+		Account: receiver.Account,
+	})
+}
+
+func (s *ProfitSharing) receiver(url string, receiver *ProfitSharingReceiver) core.Responder {
+	b, err := json.Marshal(receiver)
+	if err != nil {
+		return core.Err(nil, err)
+	}
+	return s.request(url, util.Map{
+		"appid":    s.GetString("app_id"),
+		"receiver": string(b),
+	})
+}
+
+/*Share 请求单次分账
+单次分账请求按照传入的分账接收方账号和资金进行分账,同时会将订单剩余的待分账金额解冻给本商户
+接口链接:https://api.mch.weixin.qq.com/secapi/pay/profitsharing
+*/
+func (s *ProfitSharing) Share(transactionID, outOrderNo string, receivers []*ProfitSharingReceiver) core.Responder {
+	return s.share(profitSharing, transactionID, outOrderNo, receivers)
+}
+
+/*MultiShare 请求多次分账
+多次分账请求仅会按照传入的分账接收方进行分账,不会对剩余的金额进行任何操作
+接口链接:https://api.mch.weixin.qq.com/secapi/pay/multiprofitsharing
+*/
+func (s *ProfitSharing) MultiShare(transactionID, outOrderNo string, receivers []*ProfitSharingReceiver) core.Responder {
+	return s.share(multiProfitSharing, transactionID, outOrderNo, receivers)
+}
+
+func (s *ProfitSharing) share(url, transactionID, outOrderNo string, receivers []*ProfitSharingReceiver) core.Responder {
+	b, err := json.Marshal(receivers)
+	if err != nil {
+		return core.Err(nil, err)
+	}
+	return s.safeRequest(url, util.Map{
+		"appid":          s.GetString("app_id"),
+		"transaction_id": transactionID,
+		"out_order_no":   outOrderNo,
+		"receivers":      string(b),
+	})
+}
+
+/*Finish 完结分账
+不需要进行分账的订单,可直接调用本接口将订单的金额全部解冻给本商户
+amount为订单剩余的待分账金额(分),即本次解冻给本商户的金额
+接口链接:https://api.mch.weixin.qq.com/secapi/pay/profitsharingfinish
+*/
+func (s *ProfitSharing) Finish(transactionID, outOrderNo string, amount int, description string, option ...util.Map) core.Responder {
+	m := util.MapsToMap(util.Map{
+		"appid":          s.GetString("app_id"),
+		"transaction_id": transactionID,
+		"out_order_no":   outOrderNo,
+		"amount":         strconv.Itoa(amount),
+		"description":    description,
+	}, option)
+	return s.safeRequest(profitSharingFinish, m)
+}
+
+/*Query 查询分账结果
+接口链接:https://api.mch.weixin.qq.com/pay/profitsharingquery
+*/
+func (s *ProfitSharing) Query(transactionID, outOrderNo string) core.Responder {
+	return s.request(profitSharingQuery, util.Map{
+		"transaction_id": transactionID,
+		"out_order_no":   outOrderNo,
+	})
+}
+
+/*Return 分账回退
+对订单进行分账后,将分给某个接收方的金额从该接收方回退给本商户
+接口链接:https://api.mch.weixin.qq.com/secapi/pay/profitsharingreturn
+*/
+func (s *ProfitSharing) Return(outOrderNo, outReturnNo, returnAccount string, amount int, description string) core.Responder {
+	return s.safeRequest(profitSharingReturn, util.Map{
+		"appid":               s.GetString("app_id"),
+		"out_order_no":        outOrderNo,
+		"out_return_no":       outReturnNo,
+		"return_account_type": ReceiverTypeMerchantID,
+		"return_account":      returnAccount,
+		"return_amount":       strconv.Itoa(amount),
+		"description":         description,
+	})
+}
+
+/*ReturnQuery 回退结果查询
+接口链接:https://api.mch.weixin.qq.com/pay/profitsharingreturnquery
+*/
+func (s *ProfitSharing) ReturnQuery(outOrderNo, outReturnNo string) core.Responder {
+	return s.request(profitSharingReturnQuery, util.Map{
+		"appid":         s.GetString("app_id"),
+		"out_order_no":  outOrderNo,
+		"out_return_no": outReturnNo,
+	})
+}
+
+//request 分账接口仅支持HMAC-SHA256签名,添加/删除接收方及查询接口不需要证书
+func (s *ProfitSharing) request(url string, m util.Map) core.Responder {
+	m.Set("sign_type", util.HMACSHA256)
+	return s.Request(url, m)
+}
+
+//safeRequest 分账,完结分账及分账回退需要双向证书
+func (s *ProfitSharing) safeRequest(url string, m util.Map) core.Responder {
+	m.Set("sign_type", util.HMACSHA256)
+	return s.SafeRequest(url, m)
+}
@@ -1,12 +1,123 @@
 package payment
 
 import (
+	"encoding/xml"
+	"errors"
 	"strconv"
 
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/util"
 )
 
+// RedPackScene 发放红包使用场景
+type RedPackScene string
+
+/*红包使用场景 */
+const (
+	RedPackSceneProductPromotion RedPackScene = "PRODUCT_1" //商品促销
+	RedPackSceneLottery          RedPackScene = "PRODUCT_2" //抽奖
+	RedPackSceneVirtualGoods     RedPackScene = "PRODUCT_3" //虚拟物品兑奖
+	RedPackSceneWelfare          RedPackScene = "PRODUCT_4" //企业内部福利
+	RedPackSceneChannel          RedPackScene = "PRODUCT_5" //渠道分润
+	RedPackSceneInsurance        RedPackScene = "PRODUCT_6" //保险回馈
+	RedPackSceneLotteryPrize     RedPackScene = "PRODUCT_7" //彩票派奖
+	RedPackSceneTax              RedPackScene = "PRODUCT_8" //税务刮奖
+)
+
+/*RedPackRequest 红包发放参数
+TotalAmount单位为分,红包金额大于200元或者小于1元时SceneID必填
+*/
+type RedPackRequest struct {
+	MchBillNo    string       // mch_billno 商户订单号
+	SendName     string       // send_name 商户名称
+	ReOpenID     string       // re_openid 用户openid
+	TotalAmount  int          // total_amount 付款金额
+	TotalNum     int          // total_num 红包发放总人数(裂变红包)
+	Wishing      string       // wishing 红包祝福语
+	ActName      string       // act_name 活动名称
+	Remark       string       // remark 备注
+	SceneID      RedPackScene // scene_id 场景id
+	RiskInfo     string       // risk_info 活动信息
+	ConsumeMchID string       // consume_mch_id 扣钱方mchid
+	MsgAppID     string       // msgappid 触达用户appid
+}
+
+// Validate 校验红包发放参数
+func (req *RedPackRequest) Validate() error {
+	if req.MchBillNo == "" || req.SendName == "" || req.ReOpenID == "" ||
+		req.Wishing == "" || req.ActName == "" || req.Remark == "" {
+		return ErrRedPackRequired
+	}
+	if req.TotalAmount <= 0 {
+		return ErrRedPackAmount
+	}
+	if (req.TotalAmount < 100 || req.TotalAmount > 20000) && req.SceneID == "" {
+		return ErrRedPackSceneRequired
+	}
+	return nil
+}
+
+// ToMap ...
+func (req *RedPackRequest) ToMap() util.Map {
+	m := util.Map{
+		"mch_billno":   req.MchBillNo,
+		"send_name":    req.SendName,
+		"re_openid":    req.ReOpenID,
+		"total_amount": strconv.Itoa(req.TotalAmount),
+		"wishing":      req.Wishing,
+		"act_name":     req.ActName,
+		"remark":       req.Remark,
+	}
+	if req.TotalNum > 0 {
+		m.Set("total_num", strconv.Itoa(req.TotalNum))
+	}
+	if req.SceneID != "" {
+		m.Set("scene_id", string(req.SceneID))
+	}
+	if req.RiskInfo != "" {
+		m.Set("risk_info", req.RiskInfo)
+	}
+	if req.ConsumeMchID != "" {
+		m.Set("consume_mch_id", req.ConsumeMchID)
+	}
+	if req.MsgAppID != "" {
+		m.Set("msgappid", req.MsgAppID)
+	}
+	return m
+}
+
+// RedPackReceiver 红包领取记录
+type RedPackReceiver struct {
+	OpenID  string `xml:"openid"`
+	Amount  int    `xml:"amount"`
+	RcvTime string `xml:"rcv_time"`
+}
+
+// RedPackInfo 红包查询结果
+type RedPackInfo struct {
+	ReturnCode   string            `xml:"return_code"`
+	ReturnMsg    string            `xml:"return_msg"`
+	ResultCode   string            `xml:"result_code"`
+	ErrCode      string            `xml:"err_code"`
+	ErrCodeDes   string            `xml:"err_code_des"`
+	MchBillNo    string            `xml:"mch_billno"`
+	MchID        string            `xml:"mch_id"`
+	DetailID     string            `xml:"detail_id"`
+	Status       string            `xml:"status"`    // SENDING/SENT/FAILED/RECEIVED/RFUND_ING/REFUND
+	SendType     string            `xml:"send_type"` // API/UPLOAD/ACTIVITY
+	HbType       string            `xml:"hb_type"`   // GROUP/NORMAL
+	TotalNum     int               `xml:"total_num"`
+	TotalAmount  int               `xml:"total_amount"`
+	Reason       string            `xml:"reason"`
+	SendTime     string            `xml:"send_time"`
+	RefundTime   string            `xml:"refund_time"`
+	RefundAmount int               `xml:"refund_amount"`
+	Wishing      string            `xml:"wishing"`
+	Remark       string            `xml:"remark"`
+	ActName      string            `xml:"act_name"`
+	HbList       []RedPackReceiver `xml:"hblist>hbinfo"`
+}
+
 /*RedPack RedPack */
 type RedPack struct {
 	*Payment
@@ -35,13 +146,32 @@ func NewRedPack(config *core.Config) *RedPack {
 func (r *RedPack) Info(mchBillNo string) core.Responder {
 	m := util.Map{
 		"mch_billno": mchBillNo,
-		"appid":      "app_id",
+		"appid":      r.Get("app_id"),
 		"bill_type":  "MCHT",
 	}
 	return r.SafeRequest(mmpaymkttransfersGetHbInfo, m)
 
 }
 
+// InfoResult 查询红包记录并解析结果
+func (r *RedPack) InfoResult(mchBillNo string) (*RedPackInfo, error) {
+	resp := r.Info(mchBillNo)
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	info := &RedPackInfo{}
+	if err := xml.Unmarshal(resp.Bytes(), info); err != nil {
+		return nil, err
+	}
+	if info.ReturnCode != "SUCCESS" {
+		return info, errors.New(info.ReturnMsg)
+	}
+	if info.ResultCode != "SUCCESS" {
+		return info, errors.New(info.ErrCodeDes)
+	}
+	return info, nil
+}
+
 /*SendNormal 发放普通红包
 发放规则
 1.发送频率限制------默认1800/min
@@ -113,6 +243,14 @@ func (r *RedPack) SendNormal(m util.Map) core.Responder {
 	return r.SafeRequest(mmpaymkttransfersSendRedPack, m)
 }
 
+// SendNormalRequest 使用RedPackRequest发放普通红包
+func (r *RedPack) SendNormalRequest(req *RedPackRequest) core.Responder {
+	if err := req.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	return r.SendNormal(req.ToMap())
+}
+
 /*SendGroup 裂变红包
 发放规则
 裂变红包:一次可以发放一组红包。首先领取的用户为种子用户，种子用户领取一组红包当中的一个，并可以通过社交分享将剩下的红包给其他用户。裂变红包充分利用了人际传播的优势。
@@ -178,3 +316,14 @@ func (r *RedPack) SendGroup(m util.Map) core.Responder {
 	m.Set("wxappid", r.Get("app_id"))
 	return r.SafeRequest(mmpaymkttransfersSendGroupRedPack, m)
 }
+
+// SendGroupRequest 使用RedPackRequest发放裂变红包,TotalNum为红包发放总人数(3-20)
+func (r *RedPack) SendGroupRequest(req *RedPackRequest) core.Responder {
+	if err := req.Validate(); err != nil {
+		return core.Err(nil, err)
+	}
+	if req.TotalNum < 3 || req.TotalNum > 20 {
+		return core.Err(nil, ErrRedPackTotalNum)
+	}
+	return r.SendGroup(req.ToMap())
+}
@@ -85,6 +85,15 @@ func (r *Refund) QueryByOutTradeNumber(id string) core.Responder {
 	return r.query(util.Map{"out_trade_no": id})
 }
 
+/*QueryByOutTradeNumberWithOffset 按out_trade_no分页查找退款订单
+接口地址
+接口链接:https://api.mch.weixin.qq.com/pay/refundquery
+偏移量	offset	否	Int	15	偏移量，当部分退款次数超过10次时可使用，表示返回的查询结果从这个偏移量开始取记录
+*/
+func (r *Refund) QueryByOutTradeNumberWithOffset(id string, offset int) core.Responder {
+	return r.query(util.Map{"out_trade_no": id, "offset": strconv.Itoa(offset)})
+}
+
 /*QueryByTransactionID 按transaction_id查找退款订单
 接口地址
 接口链接:https://api.mch.weixin.qq.com/pay/refundquery
@@ -0,0 +1,405 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/godcong/wego/cache"
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/log"
+	"github.com/godcong/wego/util"
+)
+
+// RefundAccountUnsettled 未结算资金退款(默认)
+const RefundAccountUnsettled = "REFUND_SOURCE_UNSETTLED_FUNDS"
+
+// RefundAccountRecharge 可用余额退款
+const RefundAccountRecharge = "REFUND_SOURCE_RECHARGE_FUNDS"
+
+// refundLedgerRetry 写入退款记录时compare-and-swap的最多尝试次数
+const refundLedgerRetry = 5
+
+/*RefundEntryStatus 退款记录状态 */
+type RefundEntryStatus string
+
+/*RefundEntryStatus types */
+const (
+	RefundEntryPending   RefundEntryStatus = "PENDING"   //已发起,结果未知(超时,SYSTEMERROR等),需通过Reconcile确认
+	RefundEntryConfirmed RefundEntryStatus = "CONFIRMED" //微信已受理(SUCCESS/PROCESSING)
+)
+
+/*RefundEntry 单笔退款记录 */
+type RefundEntry struct {
+	RefundFee int               `json:"refund_fee"`
+	Status    RefundEntryStatus `json:"status"`
+}
+
+/*RefundEntries 订单的退款记录,以out_refund_no为key */
+type RefundEntries map[string]*RefundEntry
+
+// Total 退款记录总金额(包含结果未知的退款)
+func (e RefundEntries) Total() int {
+	total := 0
+	for _, v := range e {
+		total += v.RefundFee
+	}
+	return total
+}
+
+func (e RefundEntries) clone() RefundEntries {
+	entries := make(RefundEntries, len(e))
+	for k, v := range e {
+		entry := *v
+		entries[k] = &entry
+	}
+	return entries
+}
+
+/*RefundStorage 订单退款记录存储
+CompareAndSwap仅在当前记录仍为old时写入new,多进程部署时须由存储本身保证原子性(如redis的WATCH/MULTI或lua脚本)
+*/
+type RefundStorage interface {
+	Load(tradeNum string) (RefundEntries, error)
+	CompareAndSwap(tradeNum string, old, new RefundEntries) (bool, error)
+}
+
+// cacheRefundMutex 保护cacheRefundStorage的compare-and-swap
+var cacheRefundMutex sync.Mutex
+
+type cacheRefundStorage struct {
+	prefix string
+}
+
+/*CacheRefundStorage 使用cache保存退款记录
+compare-and-swap通过进程内的锁实现,仅适用于单进程部署;多进程共享同一商户号时请自行实现RefundStorage
+*/
+func CacheRefundStorage(prefix string) RefundStorage {
+	return &cacheRefundStorage{
+		prefix: prefix,
+	}
+}
+
+func (s *cacheRefundStorage) getCacheKey(tradeNum string) string {
+	return "godcong.wego.payment.refund." + s.prefix + "." + fmt.Sprintf("%x", md5.Sum([]byte(tradeNum)))
+}
+
+// Load ...
+func (s *cacheRefundStorage) Load(tradeNum string) (RefundEntries, error) {
+	data, err := s.load(tradeNum)
+	if err != nil {
+		return nil, err
+	}
+	entries := RefundEntries{}
+	if len(data) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CompareAndSwap ...
+func (s *cacheRefundStorage) CompareAndSwap(tradeNum string, old, new RefundEntries) (bool, error) {
+	cacheRefundMutex.Lock()
+	defer cacheRefundMutex.Unlock()
+
+	cur, err := s.load(tradeNum)
+	if err != nil {
+		return false, err
+	}
+	if len(old) == 0 {
+		if len(cur) != 0 {
+			return false, nil
+		}
+	} else {
+		o, err := json.Marshal(old)
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(cur, o) {
+			return false, nil
+		}
+	}
+
+	n, err := json.Marshal(new)
+	if err != nil {
+		return false, err
+	}
+	cache.Set(s.getCacheKey(tradeNum), string(n))
+	return true, nil
+}
+
+func (s *cacheRefundStorage) load(tradeNum string) ([]byte, error) {
+	v := cache.Get(s.getCacheKey(tradeNum))
+	if v == nil {
+		return nil, nil
+	}
+	str, b := util.ParseString(v)
+	if !b {
+		return nil, fmt.Errorf("invalid refund entries: %v", v)
+	}
+	if str == "" || str == "{}" {
+		return nil, nil
+	}
+	return []byte(str), nil
+}
+
+/*RefundLedger 部分退款台账
+按out_refund_no记录每个订单的退款,发起退款前校验剩余可退金额:
+1.发起前以PENDING状态预留退款金额,重复的out_refund_no视为重试,不重复计算
+2.退款成功后标记为CONFIRMED,明确失败时删除记录
+3.超时/SYSTEMERROR等结果未知的退款保持PENDING并继续占用金额,通过Reconcile按退款查询结果确认
+*/
+type RefundLedger struct {
+	*Refund
+	storage RefundStorage
+	account string
+}
+
+/*NewRefundLedger NewRefundLedger */
+func NewRefundLedger(config *core.Config, storage RefundStorage) *RefundLedger {
+	return NewRefund(config).Ledger(storage)
+}
+
+// Ledger 创建退款台账,storage为nil时使用cache
+func (r *Refund) Ledger(storage RefundStorage) *RefundLedger {
+	if storage == nil {
+		storage = CacheRefundStorage(r.GetString("mch_id"))
+	}
+	return &RefundLedger{
+		Refund:  r,
+		storage: storage,
+	}
+}
+
+// SetAccount 设置退款资金来源(refund_account)
+func (l *RefundLedger) SetAccount(account string) *RefundLedger {
+	l.account = account
+	return l
+}
+
+// Entries 订单的退款记录
+func (l *RefundLedger) Entries(tradeNum string) (RefundEntries, error) {
+	return l.storage.Load(tradeNum)
+}
+
+// Refunded 订单累计退款金额(包含结果未知的退款)
+func (l *RefundLedger) Refunded(tradeNum string) (int, error) {
+	entries, err := l.storage.Load(tradeNum)
+	if err != nil {
+		return 0, err
+	}
+	return entries.Total(), nil
+}
+
+// Remaining 订单剩余可退金额
+func (l *RefundLedger) Remaining(tradeNum string, total int) (int, error) {
+	refunded, err := l.Refunded(tradeNum)
+	if err != nil {
+		return 0, err
+	}
+	return total - refunded, nil
+}
+
+/*ByOutTradeNumber 按照out_trade_no发起部分退款
+校验累计退款金额不超过订单金额后调用退款接口,并按结果更新退款记录
+接口链接:https://api.mch.weixin.qq.com/secapi/pay/refund
+*/
+func (l *RefundLedger) ByOutTradeNumber(tradeNum, num string, total, refund int, option ...util.Map) core.Responder {
+	return l.refund(tradeNum, num, total, refund, option)
+}
+
+// RefundItem 批量退款中的单笔退款
+type RefundItem struct {
+	OutRefundNo string
+	RefundFee   int
+}
+
+/*Batch 按照out_trade_no批量发起部分退款
+先校验本批次退款总额不超过剩余可退金额,再依次发起退款,遇到失败即停止
+返回已发起退款的结果
+*/
+func (l *RefundLedger) Batch(tradeNum string, total int, items []RefundItem, option ...util.Map) ([]core.Responder, error) {
+	entries, err := l.storage.Load(tradeNum)
+	if err != nil {
+		return nil, err
+	}
+	sum := entries.Total()
+	for _, item := range items {
+		if _, b := entries[item.OutRefundNo]; !b {
+			sum += item.RefundFee
+		}
+	}
+	if sum > total {
+		return nil, ErrRefundExceeded
+	}
+
+	var resps []core.Responder
+	for _, item := range items {
+		resp := l.refund(tradeNum, item.OutRefundNo, total, item.RefundFee, option)
+		resps = append(resps, resp)
+		rlt, err := resp.Result()
+		if err != nil {
+			return resps, err
+		}
+		if rlt.GetString("return_code") != "SUCCESS" {
+			return resps, fmt.Errorf("%s", rlt.GetString("return_msg"))
+		}
+		if rlt.GetString("result_code") != "SUCCESS" {
+			return resps, fmt.Errorf("%s", rlt.GetString("err_code_des"))
+		}
+	}
+	return resps, nil
+}
+
+func (l *RefundLedger) refund(tradeNum, num string, total, refund int, option []util.Map) core.Responder {
+	if refund <= 0 {
+		return core.Err(nil, ErrInvalidRefundFee)
+	}
+
+	confirmed := false
+	err := l.update(tradeNum, func(entries RefundEntries) error {
+		if entry, b := entries[num]; b {
+			//相同out_refund_no的重试,微信按幂等处理
+			if entry.RefundFee != refund {
+				return ErrRefundFeeMismatch
+			}
+			confirmed = entry.Status == RefundEntryConfirmed
+			return nil
+		}
+		if entries.Total()+refund > total {
+			log.Error("RefundLedger|refund", tradeNum, entries.Total(), refund, total)
+			return ErrRefundExceeded
+		}
+		entries[num] = &RefundEntry{RefundFee: refund, Status: RefundEntryPending}
+		return nil
+	})
+	if err != nil {
+		return core.Err(nil, err)
+	}
+
+	m := util.MapsToMap(util.Map{}, option)
+	if l.account != "" && !m.Has("refund_account") {
+		m.Set("refund_account", l.account)
+	}
+	resp := l.Refund.ByOutTradeNumber(tradeNum, num, total, refund, m)
+	rlt, err := resp.Result()
+
+	var status RefundEntryStatus
+	switch {
+	case err != nil || rlt.GetString("return_code") != "SUCCESS":
+		//请求失败,结果未知
+		return resp
+	case rlt.GetString("result_code") == "SUCCESS":
+		status = RefundEntryConfirmed
+	case confirmed:
+		return resp
+	default:
+		switch rlt.GetString("err_code") {
+		case "SYSTEMERROR", "BIZERR_NEED_RETRY":
+			return resp
+		}
+	}
+
+	err = l.update(tradeNum, func(entries RefundEntries) error {
+		if status == "" {
+			delete(entries, num)
+		} else if entry, b := entries[num]; b {
+			entry.Status = status
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("RefundLedger|refund", err)
+	}
+	return resp
+}
+
+// update 以compare-and-swap的方式修改退款记录
+func (l *RefundLedger) update(tradeNum string, fn func(entries RefundEntries) error) error {
+	for i := 0; i < refundLedgerRetry; i++ {
+		old, err := l.storage.Load(tradeNum)
+		if err != nil {
+			return err
+		}
+		entries := old.clone()
+		if err := fn(entries); err != nil {
+			return err
+		}
+		b, err := l.storage.CompareAndSwap(tradeNum, old, entries)
+		if err != nil {
+			return err
+		}
+		if b {
+			return nil
+		}
+	}
+	return ErrRefundLedgerConflict
+}
+
+/*Reconcile 按照退款查询结果校正退款记录
+退款状态为SUCCESS或PROCESSING的退款记为CONFIRMED,其他状态及查询不到的PENDING记录将被删除,
+退款笔数超过单次返回数量时按offset分页查询;应在该订单没有进行中的退款请求时调用
+返回校正后的累计退款金额
+*/
+func (l *RefundLedger) Reconcile(tradeNum string) (int, error) {
+	queried := RefundEntries{}
+	offset := 0
+	for {
+		rlt, err := l.QueryByOutTradeNumberWithOffset(tradeNum, offset).Result()
+		if err != nil {
+			return 0, err
+		}
+		if rlt.GetString("return_code") != "SUCCESS" {
+			return 0, fmt.Errorf("%s", rlt.GetString("return_msg"))
+		}
+		if rlt.GetString("result_code") != "SUCCESS" {
+			//没有退款记录
+			if rlt.GetString("err_code") == "REFUNDNOTEXIST" {
+				break
+			}
+			return 0, fmt.Errorf("%s", rlt.GetString("err_code_des"))
+		}
+
+		count, _ := strconv.Atoi(rlt.GetString("refund_count"))
+		for i := 0; i < count; i++ {
+			idx := strconv.Itoa(i)
+			switch rlt.GetString("refund_status_" + idx) {
+			case "SUCCESS", "PROCESSING":
+				fee, _ := strconv.Atoi(rlt.GetString("refund_fee_" + idx))
+				queried[rlt.GetString("out_refund_no_"+idx)] = &RefundEntry{
+					RefundFee: fee,
+					Status:    RefundEntryConfirmed,
+				}
+			}
+		}
+
+		total, err := strconv.Atoi(rlt.GetString("total_refund_count"))
+		offset += count
+		if err != nil || count == 0 || offset >= total {
+			break
+		}
+	}
+
+	var refunded int
+	err := l.update(tradeNum, func(entries RefundEntries) error {
+		for k := range entries {
+			delete(entries, k)
+		}
+		for k, v := range queried {
+			entry := *v
+			entries[k] = &entry
+		}
+		refunded = entries.Total()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return refunded, nil
+}
@@ -1,6 +1,12 @@
 package payment
 
 import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godcong/wego/cache"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/util"
 )
@@ -40,5 +46,49 @@ func (s *Security) GetPublicKey() core.Responder {
 		core.DataTypeXML:      s.initRequest(m),
 		core.DataTypeSecurity: s.Config,
 	}
-	return core.Request(riskGetPublicKey, core.POST, maps)
+	return core.Request(core.POST, riskGetPublicKey, maps)
+}
+
+/*PublicKey 获取RSA加密公钥(PKCS#1 PEM格式)
+优先从缓存读取,不存在或已过期时调用GetPublicKey获取并缓存,
+缓存时长为配置项pubkey_ttl(秒),未配置时为DefaultPublicKeyTTL
+*/
+func (s *Security) PublicKey() ([]byte, error) {
+	key := cache.Get(s.getCacheKey())
+	if v, b := util.ParseString(key); b && v != "" {
+		return []byte(v), nil
+	}
+	return s.RefreshPublicKey()
+}
+
+/*RefreshPublicKey 重新获取RSA加密公钥并更新缓存,微信更换公钥后使用 */
+func (s *Security) RefreshPublicKey() ([]byte, error) {
+	rlt, err := s.GetPublicKey().Result()
+	if err != nil {
+		return nil, err
+	}
+	if rlt.GetString("return_code") != "SUCCESS" {
+		return nil, errors.New(rlt.GetString("return_msg"))
+	}
+	if rlt.GetString("result_code") != "SUCCESS" {
+		return nil, errors.New(rlt.GetString("err_code_des"))
+	}
+	pub := rlt.GetString("pub_key")
+	if pub == "" {
+		return nil, ErrNilPublicKey
+	}
+	ttl := time.Now().Add(s.publicKeyTTL())
+	cache.SetWithTTL(s.getCacheKey(), pub, &ttl)
+	return []byte(pub), nil
+}
+
+func (s *Security) publicKeyTTL() time.Duration {
+	if ttl := s.GetIntD("pubkey_ttl", 0); ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+	return DefaultPublicKeyTTL
+}
+
+func (s *Security) getCacheKey() string {
+	return "godcong.wego.payment.security.pubkey." + fmt.Sprintf("%x", md5.Sum([]byte(s.GetString("mch_id"))))
 }
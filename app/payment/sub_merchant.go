@@ -0,0 +1,109 @@
+package payment
+
+import (
+	"sync"
+
+	"github.com/godcong/wego/util"
+)
+
+// SubMerchant 服务商模式下的子商户
+type SubMerchant struct {
+	MchID string // sub_mch_id
+	AppID string // sub_appid
+	Name  string
+}
+
+// SubMerchants 子商户注册表
+type SubMerchants struct {
+	mutex     sync.RWMutex
+	merchants map[string]*SubMerchant
+}
+
+// NewSubMerchants ...
+func NewSubMerchants() *SubMerchants {
+	return &SubMerchants{
+		merchants: make(map[string]*SubMerchant),
+	}
+}
+
+// Register 注册子商户,已存在时覆盖
+func (s *SubMerchants) Register(sub *SubMerchant) *SubMerchants {
+	if sub == nil || sub.MchID == "" {
+		return s
+	}
+	v := *sub
+	s.mutex.Lock()
+	s.merchants[sub.MchID] = &v
+	s.mutex.Unlock()
+	return s
+}
+
+// Get 获取子商户
+func (s *SubMerchants) Get(mchID string) (*SubMerchant, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	sub, b := s.merchants[mchID]
+	if !b {
+		return nil, false
+	}
+	v := *sub
+	return &v, true
+}
+
+// Remove 删除子商户
+func (s *SubMerchants) Remove(mchID string) *SubMerchants {
+	s.mutex.Lock()
+	delete(s.merchants, mchID)
+	s.mutex.Unlock()
+	return s
+}
+
+// List 获取全部子商户
+func (s *SubMerchants) List() []*SubMerchant {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var list []*SubMerchant
+	for _, sub := range s.merchants {
+		v := *sub
+		list = append(list, &v)
+	}
+	return list
+}
+
+// SubMerchants 获取子商户注册表
+func (p *Payment) SubMerchants() *SubMerchants {
+	return p.subMerchants
+}
+
+// SubMerchant 获取当前请求的子商户,非服务商模式返回nil
+func (p *Payment) SubMerchant() *SubMerchant {
+	if p.subMerchant == nil {
+		return nil
+	}
+	v := *p.subMerchant
+	return &v
+}
+
+/*WithSubMerchant 以子商户身份发起请求
+返回新的Payment,不修改共享配置,可在并发请求中为不同子商户使用
+*/
+func (p *Payment) WithSubMerchant(mchID, appID string) *Payment {
+	return &Payment{
+		Config:       p.Config,
+		Module:       util.Map{},
+		prefix:       p.prefix,
+		subMerchant:  &SubMerchant{MchID: mchID, AppID: appID},
+		subMerchants: p.subMerchants,
+	}
+}
+
+// OnBehalfOf 以注册表中的子商户身份发起请求
+func (p *Payment) OnBehalfOf(mchID string) (*Payment, error) {
+	sub, b := p.subMerchants.Get(mchID)
+	if !b {
+		return nil, ErrSubMerchantNotFound
+	}
+	pay := p.WithSubMerchant(sub.MchID, sub.AppID)
+	pay.subMerchant.Name = sub.Name
+	return pay, nil
+}
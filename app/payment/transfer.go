@@ -1,13 +1,70 @@
 package payment
 
 import (
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"github.com/godcong/wego/cipher"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/log"
 	"github.com/godcong/wego/util"
+	"io/ioutil"
+	"strconv"
 )
 
+/*BankCardRequest 企业付款到银行卡参数
+BankNo,TrueName使用明文,发起请求时自动进行RSA加密
+BankCode为空时根据BankName从银行编号列表中查找
+*/
+type BankCardRequest struct {
+	PartnerTradeNo string // partner_trade_no 商户企业付款单号
+	BankNo         string // enc_bank_no 收款方银行卡号
+	TrueName       string // enc_true_name 收款方用户名
+	BankCode       string // bank_code 收款方开户行
+	BankName       string
+	Amount         int    // amount 付款金额
+	Desc           string // desc 付款说明
+}
+
+// ToMap ...
+func (req *BankCardRequest) ToMap() util.Map {
+	code := req.BankCode
+	if code == "" {
+		code, _ = BankCode(req.BankName)
+	}
+	m := util.Map{
+		"partner_trade_no": req.PartnerTradeNo,
+		"enc_bank_no":      req.BankNo,
+		"enc_true_name":    req.TrueName,
+		"bank_code":        code,
+		"amount":           strconv.Itoa(req.Amount),
+	}
+	if req.Desc != "" {
+		m.Set("desc", req.Desc)
+	}
+	return m
+}
+
+// BankCardOrder 企业付款到银行卡查询结果
+type BankCardOrder struct {
+	ReturnCode     string `xml:"return_code"`
+	ReturnMsg      string `xml:"return_msg"`
+	ResultCode     string `xml:"result_code"`
+	ErrCode        string `xml:"err_code"`
+	ErrCodeDes     string `xml:"err_code_des"`
+	MchID          string `xml:"mch_id"`
+	PartnerTradeNo string `xml:"partner_trade_no"`
+	PaymentNo      string `xml:"payment_no"`
+	BankNoMd5      string `xml:"bank_no_md5"`
+	TrueNameMd5    string `xml:"true_name_md5"`
+	Amount         int    `xml:"amount"`
+	Status         string `xml:"status"` // PROCESSING/SUCCESS/FAILED/BANK_FAIL
+	CmmsAmt        int    `xml:"cmms_amt"`
+	CreateTime     string `xml:"create_time"`
+	PaySuccTime    string `xml:"pay_succ_time"`
+	Reason         string `xml:"reason"`
+}
+
 /*Transfer Transfer */
 type Transfer struct {
 	*Payment
@@ -129,6 +186,25 @@ func (t *Transfer) QueryBankCardOrder(s string) core.Responder {
 	return t.SafeRequest(mmpaysptransQueryBank, m)
 }
 
+// QueryBankCardOrderResult 查询企业付款银行卡并解析结果
+func (t *Transfer) QueryBankCardOrderResult(s string) (*BankCardOrder, error) {
+	resp := t.QueryBankCardOrder(s)
+	if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	order := &BankCardOrder{}
+	if err := xml.Unmarshal(resp.Bytes(), order); err != nil {
+		return nil, err
+	}
+	if order.ReturnCode != "SUCCESS" {
+		return order, errors.New(order.ReturnMsg)
+	}
+	if order.ResultCode != "SUCCESS" {
+		return order, errors.New(order.ErrCodeDes)
+	}
+	return order, nil
+}
+
 /*ToBankCard 转账至银行卡
 接口介绍
 业务流程	接口	简介
@@ -180,16 +256,50 @@ string(32)
 手续费金额	cmms_amt	是	int	手续费金额 RMB:分
 */
 func (t *Transfer) ToBankCard(maps util.Map) core.Responder {
-	if v := maps.Check("bank_code", "partner_trade_no", "enc_bank_no", "enc_true_name", "amount"); v != -1 {
+	keys := []string{"bank_code", "partner_trade_no", "enc_bank_no", "enc_true_name", "amount"}
+	if v := maps.Check(keys...); v != -1 {
 		log.Error(fmt.Sprintf("the %d index of value is required", v))
-		return nil
+		return core.Err(nil, fmt.Errorf("%s is required", keys[v]))
+	}
+	if _, b := BankName(maps.GetString("bank_code")); !b {
+		log.Error("unknown bank_code", maps.GetString("bank_code"))
+		return core.Err(nil, ErrUnknownBank)
 	}
 
-	maps.Set("mch_id", t.Get("mch_id"))
-	maps.Set("nonce_str", util.GenerateUUID())
+	pub, err := t.publicKey()
+	if err != nil {
+		return core.Err(nil, err)
+	}
+	//使用副本加密,重试时传入的maps仍为明文
+	p := maps.Clone()
+	for _, k := range []string{"enc_bank_no", "enc_true_name"} {
+		enc, err := cipher.RSAEncryptWithKey(pub, p.GetString(k))
+		if err != nil {
+			return core.Err(nil, err)
+		}
+		p.Set(k, enc)
+	}
 
-	maps.Set("enc_bank_no", cipher.RSAEncrypt(t.GetString("pubkey_path"), maps.GetString("enc_bank_no")))
-	maps.Set("enc_true_name", cipher.RSAEncrypt(t.GetString("pubkey_path"), maps.GetString("enc_true_name")))
-	maps.Set("sign", util.GenerateSignature(maps, t.GetString("key"), util.MakeSignMD5))
-	return t.SafeRequest(mmpaysptransPayBank, maps)
+	p.Set("mch_id", t.Get("mch_id"))
+	return t.SafeRequest(mmpaysptransPayBank, p)
+}
+
+// ToBankCardRequest 使用BankCardRequest转账至银行卡
+func (t *Transfer) ToBankCardRequest(req *BankCardRequest) core.Responder {
+	if req.Amount <= 0 {
+		return core.Err(nil, ErrTransferAmount)
+	}
+	m := req.ToMap()
+	if m.GetString("bank_code") == "" {
+		return core.Err(nil, ErrUnknownBank)
+	}
+	return t.ToBankCard(m)
+}
+
+//publicKey 未配置pubkey_path时通过GetPublicKey获取
+func (t *Transfer) publicKey() ([]byte, error) {
+	if path := t.GetString("pubkey_path"); path != "" {
+		return ioutil.ReadFile(path)
+	}
+	return t.Security().PublicKey()
 }
@@ -73,7 +73,7 @@ func (m *MapCache) Has(key string) bool {
 
 /*Delete one value */
 func (m *MapCache) Delete(key string) Cache {
-	m.Delete(key)
+	m.Map.Delete(key)
 	return m
 }
 
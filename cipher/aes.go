@@ -163,3 +163,21 @@ func (c *DataCrypt) Decrypt(data, iv, key string) ([]byte, error) {
 
 	return PKCS7UnPadding(dData), nil
 }
+
+/*AEADAES256GCMDecrypt 解密AEAD_AES_256_GCM加密的数据(微信支付APIv3通知)
+key为APIv3密钥,text为base64编码的密文 */
+func AEADAES256GCMDecrypt(key, nonce, associatedData, text []byte) ([]byte, error) {
+	data, err := Base64Decode(text)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, nonce, data, associatedData)
+}
@@ -1,6 +1,9 @@
 package cipher
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
 	"encoding/xml"
 	"github.com/godcong/wego/util"
 	"strings"
@@ -38,3 +41,18 @@ func TestRefundedNotify_ServeHTTP(t *testing.T) {
 	t.Log(maps)
 	t.Log(err)
 }
+
+// TestAEADAES256GCMDecrypt ...
+func TestAEADAES256GCMDecrypt(t *testing.T) {
+	key := []byte("aTKnSUcTkbEnhwQNdutWkQxAjnhAz2jK")
+	nonce := []byte("fdasflkja484")
+	ad := []byte("transaction")
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	text := base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, []byte(`{"mchid":"1900000100"}`), ad))
+
+	b, err := AEADAES256GCMDecrypt(key, nonce, ad, []byte(text))
+	if err != nil || string(b) != `{"mchid":"1900000100"}` {
+		t.Error(string(b), err)
+	}
+}
@@ -3,6 +3,7 @@ package cipher
 import (
 	"encoding/base64"
 	"errors"
+	"strconv"
 
 	"github.com/godcong/wego/util"
 )
@@ -61,25 +62,53 @@ func NewBizMsg(token, key, id string) *BizMsg {
 
 // Encrypt ...
 func (m *BizMsg) Encrypt(text, timeStamp, nonce string) (string, error) {
+	p, err := m.encrypt(text, timeStamp, nonce)
+	if err != nil {
+		return "", err
+	}
+	return string(p.ToXML()), nil
+}
+
+// EncryptJSON 加密并返回json格式
+func (m *BizMsg) EncryptJSON(text, timeStamp, nonce string) (string, error) {
+	p, err := m.encrypt(text, timeStamp, nonce)
+	if err != nil {
+		return "", err
+	}
+	if ts, err := strconv.ParseInt(timeStamp, 10, 64); err == nil {
+		p.Set("TimeStamp", ts)
+	}
+	return string(p.ToJSON()), nil
+}
+
+func (m *BizMsg) encrypt(text, timeStamp, nonce string) (util.Map, error) {
 	prp := NewPrp(m.encodingAESKey)
 	b, err := prp.Encrypt(text, m.appID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	p := util.Map{
-		"RSAEncrypt":   string(b),
+	return util.Map{
+		"Encrypt":      string(b),
 		"MsgSignature": SHA1(m.token, timeStamp, nonce, string(b)),
 		"TimeStamp":    timeStamp,
 		"Nonce":        nonce,
-	}
-	return string(p.ToXML()), nil
+	}, nil
 }
 
 // Decrypt ...
 func (m *BizMsg) Decrypt(text string, msgSignature, timeStamp, nonce string) ([]byte, error) {
 	p := util.XMLToMap([]byte(text))
-	enpt := p.GetString("RSAEncrypt")
+	return m.decrypt(p.GetString("Encrypt"), msgSignature, timeStamp, nonce)
+}
+
+// DecryptJSON 解密json格式的消息
+func (m *BizMsg) DecryptJSON(text string, msgSignature, timeStamp, nonce string) ([]byte, error) {
+	p := util.JSONToMap([]byte(text))
+	return m.decrypt(p.GetString("Encrypt"), msgSignature, timeStamp, nonce)
+}
+
+func (m *BizMsg) decrypt(enpt string, msgSignature, timeStamp, nonce string) ([]byte, error) {
 	tSign := SHA1(m.token, timeStamp, nonce, enpt)
 	if msgSignature != tSign {
 		return nil, errors.New("ValidateSignatureError")
@@ -88,3 +117,22 @@ func (m *BizMsg) Decrypt(text string, msgSignature, timeStamp, nonce string) ([]
 	b, err := prp.Decrypt([]byte(enpt), m.appID)
 	return b, err
 }
+
+// Signature 明文模式签名 sha1(token,timestamp,nonce)
+func (m *BizMsg) Signature(timeStamp, nonce string) string {
+	return SHA1(m.token, timeStamp, nonce)
+}
+
+// VerifySignature 校验明文模式签名
+func (m *BizMsg) VerifySignature(signature, timeStamp, nonce string) bool {
+	return signature != "" && signature == m.Signature(timeStamp, nonce)
+}
+
+// DecryptEchoStr 解密安全模式下的echostr
+func (m *BizMsg) DecryptEchoStr(echoStr, msgSignature, timeStamp, nonce string) ([]byte, error) {
+	if msgSignature != SHA1(m.token, timeStamp, nonce, echoStr) {
+		return nil, errors.New("ValidateSignatureError")
+	}
+	prp := NewPrp(m.encodingAESKey)
+	return prp.Decrypt([]byte(echoStr), m.appID)
+}
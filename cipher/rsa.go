@@ -123,6 +123,9 @@ func ParseRSAPublicKeyFromPEM(key []byte) (*rsa.PublicKey, error) {
 	// Parse the key
 	parsedKey, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
+		if pkey, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+			return pkey, nil
+		}
 		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
 			parsedKey = cert.PublicKey
 		} else {
@@ -169,18 +172,27 @@ func RSAEncrypt(pub string, text string) string {
 		log.Debug(e)
 		return ""
 	}
-	key, e := ParseRSAPublicKeyFromPEM(publicKey)
+	s, e := RSAEncryptWithKey(publicKey, text)
 	if e != nil {
 		log.Debug(e)
 		return ""
 	}
+	return s
+}
+
+/*RSAEncryptWithKey encrypt text with PEM encoded public key(PKCS#1 or PKCS#8)
+use RSA/ECB/OAEPWithSHA-1AndMGF1Padding as wechat pay required */
+func RSAEncryptWithKey(publicKey []byte, text string) (string, error) {
+	key, err := ParseRSAPublicKeyFromPEM(publicKey)
+	if err != nil {
+		return "", err
+	}
 	part, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, key, []byte(text), nil)
 	if err != nil {
-		log.Debug(e)
-		return ""
+		return "", err
 	}
 
-	return base64.StdEncoding.EncodeToString(part)
+	return base64.StdEncoding.EncodeToString(part), nil
 }
 
 /*Base64Encode Base64Encode */
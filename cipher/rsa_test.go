@@ -0,0 +1,40 @@
+package cipher
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+)
+
+// TestRSAEncryptWithKey ...
+func TestRSAEncryptWithKey(t *testing.T) {
+	pri, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//wechat pay returns PKCS#1 public key
+	pub := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&pri.PublicKey),
+	})
+
+	s, err := RSAEncryptWithKey(pub, "6217001210053551022")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, pri, data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "6217001210053551022" {
+		t.Error(string(b))
+	}
+}
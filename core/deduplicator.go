@@ -0,0 +1,77 @@
+package core
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/godcong/wego/cache"
+)
+
+// DefaultDedupeTTL 默认消息去重时间,微信服务器会在15秒内重试三次
+const DefaultDedupeTTL = time.Minute
+
+// dedupeMutex 保护Check的判断与占位写入
+var dedupeMutex sync.Mutex
+
+/*Deduplicator 基于缓存过滤重复推送的消息,并缓存首次处理的回复
+判断与占位通过进程内的锁保证原子,仅适用于单进程部署;多进程共享缓存时并发到达的重复消息可能被重复处理
+*/
+type Deduplicator struct {
+	TTL    time.Duration
+	prefix string
+}
+
+/*NewDeduplicator 以prefix区分不同应用的消息 */
+func NewDeduplicator(prefix string) *Deduplicator {
+	return &Deduplicator{
+		TTL:    DefaultDedupeTTL,
+		prefix: prefix,
+	}
+}
+
+// ID 消息唯一标识,普通消息使用MsgId,事件使用FromUserName+CreateTime
+func (d *Deduplicator) ID(msg *Message) string {
+	if msg.MsgID != 0 {
+		return strconv.FormatInt(msg.MsgID, 10)
+	}
+	return msg.FromUserName.Value + strconv.FormatInt(msg.CreateTime, 10) + string(msg.Event.Event.Value)
+}
+
+// Check 判断消息是否重复,重复时返回首次处理缓存的回复(处理中时为空)
+func (d *Deduplicator) Check(msg *Message) ([]byte, bool) {
+	key := d.getCacheKey(msg)
+	dedupeMutex.Lock()
+	defer dedupeMutex.Unlock()
+	if cache.Has(key) {
+		if v, b := cache.Get(key).(string); b {
+			return []byte(v), true
+		}
+		return nil, true
+	}
+	d.set(key, "")
+	return nil, false
+}
+
+// Save 缓存消息的回复,重试时直接返回
+func (d *Deduplicator) Save(msg *Message, reply []byte) {
+	key := d.getCacheKey(msg)
+	dedupeMutex.Lock()
+	defer dedupeMutex.Unlock()
+	d.set(key, string(reply))
+}
+
+func (d *Deduplicator) set(key, val string) {
+	ttl := d.TTL
+	if ttl <= 0 {
+		ttl = DefaultDedupeTTL
+	}
+	t := time.Now().Add(ttl)
+	cache.SetWithTTL(key, val, &t)
+}
+
+func (d *Deduplicator) getCacheKey(msg *Message) string {
+	return "godcong.wego.core.deduplicator." + d.prefix + "." + fmt.Sprintf("%x", md5.Sum([]byte(d.ID(msg))))
+}
@@ -0,0 +1,39 @@
+package core_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/godcong/wego/core"
+)
+
+// TestDeduplicator_Check ...
+func TestDeduplicator_Check(t *testing.T) {
+	d := core.NewDeduplicator("wxdeduplicator")
+	msg := &core.Message{}
+	msg.MsgID = 6547288321577417999
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	first := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, dup := d.Check(msg); !dup {
+				mutex.Lock()
+				first++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if first != 1 {
+		t.Error(first)
+	}
+
+	d.Save(msg, []byte("reply"))
+	if rlt, dup := d.Check(msg); !dup || string(rlt) != "reply" {
+		t.Error(string(rlt), dup)
+	}
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"encoding/json"
+	"encoding/xml"
+
 	"github.com/godcong/wego/core/message"
 )
 
@@ -8,52 +11,81 @@ import (
 type Message struct {
 	message.Message
 	/*message*/
-	Content      message.CDATA      `xml:"content"`
-	PicURL       message.CDATA      `xml:"pic_url"`        // 图片链接（由系统生成）
-	MediaID      message.CDATA      `xml:"media_id"`       // 图片消息媒体id，可以调用多媒体文件下载接口拉取数据。
-	Title        message.CDATA      `xml:"title"`          // 标题
-	AppID        message.CDATA      `xml:"app_id"`         // 小程序appid
-	PagePath     message.CDATA      `xml:"page_path"`      // 小程序页面路径
-	ThumbURL     message.CDATA      `xml:"thumb_url"`      // 封面图片的临时cdn链接
-	ThumbMediaID message.CDATA      `xml:"thumb_media_id"` // 封面图片的临时素材id
+	Content      message.CDATA      `xml:"Content"`
+	PicURL       message.CDATA      `xml:"PicUrl"`       // 图片链接（由系统生成）
+	MediaID      message.CDATA      `xml:"MediaId"`      // 图片消息媒体id，可以调用多媒体文件下载接口拉取数据。
+	Title        message.CDATA      `xml:"Title"`        // 标题
+	AppID        message.CDATA      `xml:"AppId"`        // 小程序appid
+	PagePath     message.CDATA      `xml:"PagePath"`     // 小程序页面路径
+	ThumbURL     message.CDATA      `xml:"ThumbUrl"`     // 封面图片的临时cdn链接
+	ThumbMediaID message.CDATA      `xml:"ThumbMediaId"` // 封面图片的临时素材id
 	Items        []*message.NewItem `xml:"items"`
-	Format       message.CDATA      `xml:"format"`      // 语音格式，如amr，speex等
-	Recognition  message.CDATA      `xml:"recognition"` // 语音识别结果，UTF8编码
-	LocationX    float64            `xml:"location_x"`
-	LocationY    float64            `xml:"location_y"`
-	Scale        int64              `xml:"scale"`
-	Label        message.CDATA      `xml:"label"`
-	Description  message.CDATA      `xml:"description"` // 消息描述
-	URL          message.CDATA      `xml:"url"`
+	Format       message.CDATA      `xml:"Format"`      // 语音格式，如amr，speex等
+	Recognition  message.CDATA      `xml:"Recognition"` // 语音识别结果，UTF8编码
+	LocationX    float64            `xml:"Location_X"`
+	LocationY    float64            `xml:"Location_Y"`
+	Scale        int64              `xml:"Scale"`
+	Label        message.CDATA      `xml:"Label"`
+	Description  message.CDATA      `xml:"Description"` // 消息描述
+	URL          message.CDATA      `xml:"Url"`
 	/*event*/
 	message.Event
-	EventKey  message.CDATA `xml:"event_key"` // 事件KEY值，qrscene_为前缀，后面为二维码的参数值
-	Ticket    message.CDATA `xml:"ticket"`    // 二维码的ticket，可用来换取二维码图片
-	Latitude  float64       `xml:"latitude"`  // 地理位置纬度
-	Longitude float64       `xml:"longitude"` // 地理位置经度
-	Precision float64       `xml:"precision"` // 地理位置精度
+	EventKey  message.CDATA `xml:"EventKey"`  // 事件KEY值，qrscene_为前缀，后面为二维码的参数值
+	Ticket    message.CDATA `xml:"Ticket"`    // 二维码的ticket，可用来换取二维码图片
+	Latitude  float64       `xml:"Latitude"`  // 地理位置纬度
+	Longitude float64       `xml:"Longitude"` // 地理位置经度
+	Precision float64       `xml:"Precision"` // 地理位置精度
 
-	MenuID message.CDATA `xml:"menu_id"` // 指菜单ID，如果是个性化菜单，则可以通过这个字段，知道是哪个规则的菜单被点击了。
+	MenuID message.CDATA `xml:"MenuId"` // 指菜单ID，如果是个性化菜单，则可以通过这个字段，知道是哪个规则的菜单被点击了。
 
-	ScanCodeInfo     message.ScanCodeInfo     `xml:"scan_code_info"`     // 扫描信息
-	SendPicsInfo     message.SendPicsInfo     `xml:"send_pics_info"`     // 发送的图片信息
-	SendLocationInfo message.SendLocationInfo `xml:"send_location_info"` // 发送的位置信息
+	ScanCodeInfo     message.ScanCodeInfo     `xml:"ScanCodeInfo"`     // 扫描信息
+	SendPicsInfo     message.SendPicsInfo     `xml:"SendPicsInfo"`     // 发送的图片信息
+	SendLocationInfo message.SendLocationInfo `xml:"SendLocationInfo"` // 发送的位置信息
 
-	Status      message.CDATA `xml:"status"`       // 	发送状态为成功
-	ExpiredTime int64         `xml:"expired_time"` // 有效期 (整形)，指的是时间戳，将于该时间戳认证过期
-	FailTime    int64         `xml:"fail_time"`    // 失败发生时间 (整形)，时间戳
-	FailReason  message.CDATA `xml:"fail_reason"`  // 认证失败的原因
+	Status      message.CDATA `xml:"Status"`      // 	发送状态为成功
+	ExpiredTime int64         `xml:"ExpiredTime"` // 有效期 (整形)，指的是时间戳，将于该时间戳认证过期
+	FailTime    int64         `xml:"FailTime"`    // 失败发生时间 (整形)，时间戳
+	FailReason  message.CDATA `xml:"FailReason"`  // 认证失败的原因
 	// 名称认证成功（即命名成功）
-	UniqID      message.CDATA `xml:"uniq_id"`
-	PoiID       message.CDATA `xml:"poi_id"`
-	Result      message.CDATA `xml:"result"`
-	Msg         message.CDATA `xml:"msg"`
-	SessionFrom message.CDATA `xml:"session_from"`
+	UniqID      message.CDATA `xml:"UniqId"`
+	PoiID       message.CDATA `xml:"PoiId"`
+	Result      message.CDATA `xml:"Result"`
+	Msg         message.CDATA `xml:"Msg"`
+	SessionFrom message.CDATA `xml:"SessionFrom"`
+
+	OrderID     message.CDATA `xml:"OrderId"`
+	OrderStatus int64         `xml:"OrderStatus"`
+	ProductID   message.CDATA `xml:"ProductId"`
+	SkuInfo     message.CDATA `xml:"SkuInfo"`
+
+	raw    []byte
+	isJSON bool
+}
+
+/*ParseMessage 解析推送消息,isJSON为true时按json格式解析 */
+func ParseMessage(data []byte, isJSON bool) (*Message, error) {
+	msg := &Message{
+		raw:    data,
+		isJSON: isJSON,
+	}
+	var err error
+	if isJSON {
+		err = json.Unmarshal(data, msg)
+	} else {
+		err = xml.Unmarshal(data, msg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
 
-	OrderID     message.CDATA `xml:"order_id"`
-	OrderStatus int64         `xml:"order_status"`
-	ProductID   message.CDATA `xml:"product_id"`
-	SkuInfo     message.CDATA `xml:"sku_info"`
+/*DecodeEvent 将事件推送解析为core/message中对应的事件结构 */
+func (m *Message) DecodeEvent() (message.Eventer, error) {
+	if m.isJSON {
+		return message.DecodeEventJSON(m.raw)
+	}
+	return message.DecodeEvent(m.raw)
 }
 
 // type Article struct {
@@ -29,6 +29,29 @@ const (
 	EventTypeVerifyExpired              EventType = "verify_expired"               // 认证过期失效通知审通知
 	EventTypePoiCheckNotify             EventType = "poi_check_notify"             // 审核事件推送
 	EventTypeMerchantOrder              EventType = "merchant_order"               //订单付款通知
+	EventTypeMassSendJobFinish          EventType = "MASSSENDJOBFINISH"            // 群发结果通知
+	EventTypeViewMiniprogram            EventType = "view_miniprogram"             // 点击菜单跳转小程序的事件推送
+	EventTypeWifiConnected              EventType = "WifiConnected"                // 连网后下发消息
+	EventTypeCardPassCheck              EventType = "card_pass_check"              // 卡券审核通过
+	EventTypeCardNotPassCheck           EventType = "card_not_pass_check"          // 卡券审核未通过
+	EventTypeUserGetCard                EventType = "user_get_card"                // 领取卡券
+	EventTypeUserGiftingCard            EventType = "user_gifting_card"            // 转赠卡券
+	EventTypeUserDelCard                EventType = "user_del_card"                // 删除卡券
+	EventTypeUserConsumeCard            EventType = "user_consume_card"            // 核销卡券
+	EventTypeUserPayFromPayCell         EventType = "user_pay_from_pay_cell"       // 买单
+	EventTypeUserViewCard               EventType = "user_view_card"               // 进入会员卡
+	EventTypeUserEnterSessionFromCard   EventType = "user_enter_session_from_card" // 从卡券进入公众号会话
+	EventTypeUpdateMemberCard           EventType = "update_member_card"           // 会员卡内容更新
+	EventTypeCardSkuRemind              EventType = "card_sku_remind"              // 库存报警
+	EventTypeSubmitMemberCardUserInfo   EventType = "submit_membercard_user_info"  // 会员卡激活
+	EventTypeSubscribeMsgPopup          EventType = "subscribe_msg_popup_event"    // 订阅通知弹窗操作
+	EventTypeSubscribeMsgChange         EventType = "subscribe_msg_change_event"   // 订阅通知管理操作
+	EventTypeSubscribeMsgSent           EventType = "subscribe_msg_sent_event"     // 订阅通知发送结果
+	EventTypeWxaMediaCheck              EventType = "wxa_media_check"              // 小程序音视频异步检测结果
+	EventTypeWeappAuditSuccess          EventType = "weapp_audit_success"          // 代码审核通过
+	EventTypeWeappAuditFail             EventType = "weapp_audit_fail"             // 代码审核不通过
+	EventTypeWeappAuditDelay            EventType = "weapp_audit_delay"            // 代码审核延后
+	EventTypeAddExpressPath             EventType = "add_express_path"             // 运单轨迹更新
 )
 
 /*EVTCDATA EVTCDATA */
@@ -36,6 +59,16 @@ type EVTCDATA struct {
 	Value EventType `xml:",cdata"`
 }
 
+/*UnmarshalJSON 解析json格式推送中的Event */
+func (e *EVTCDATA) UnmarshalJSON(b []byte) error {
+	var v CDATA
+	if err := v.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	e.Value = EventType(v.Value)
+	return nil
+}
+
 /*Event Event */
 type Event struct {
 	Event EVTCDATA
@@ -0,0 +1,468 @@
+package message
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrNotEvent 消息类型不是event
+var ErrNotEvent = errors.New("message is not an event")
+
+/*EventMessage 事件推送公共字段,未注册的事件解析为该类型 */
+type EventMessage struct {
+	Message
+	Event
+}
+
+/*GetEvent 事件类型 */
+func (e *EventMessage) GetEvent() EventType {
+	return e.Event.Event.Value
+}
+
+/*Eventer 事件推送 */
+type Eventer interface {
+	GetEvent() EventType
+}
+
+/*SubscribeEvent 关注事件,扫描带参数二维码时EventKey为qrscene_前缀 */
+type SubscribeEvent struct {
+	EventMessage
+	EventKey string `xml:"EventKey"`
+	Ticket   string `xml:"Ticket"`
+}
+
+/*UnsubscribeEvent 取消关注事件 */
+type UnsubscribeEvent struct {
+	EventMessage
+}
+
+/*ScanEvent 已关注用户扫描带参数二维码事件 */
+type ScanEvent struct {
+	EventMessage
+	EventKey string `xml:"EventKey"`
+	Ticket   string `xml:"Ticket"`
+}
+
+/*LocationEvent 上报地理位置事件 */
+type LocationEvent struct {
+	EventMessage
+	Latitude  float64 `xml:"Latitude"`
+	Longitude float64 `xml:"Longitude"`
+	Precision float64 `xml:"Precision"`
+}
+
+/*ClickEvent 点击菜单拉取消息事件 */
+type ClickEvent struct {
+	EventMessage
+	EventKey string `xml:"EventKey"`
+}
+
+/*ViewEvent 点击菜单跳转链接事件,EventKey为跳转URL */
+type ViewEvent struct {
+	EventMessage
+	EventKey string `xml:"EventKey"`
+	MenuID   string `xml:"MenuId"`
+}
+
+/*ViewMiniprogramEvent 点击菜单跳转小程序事件,EventKey为小程序路径 */
+type ViewMiniprogramEvent struct {
+	EventMessage
+	EventKey string `xml:"EventKey"`
+	MenuID   string `xml:"MenuId"`
+}
+
+/*ScanCodeEvent 扫码推事件(scancode_push,scancode_waitmsg) */
+type ScanCodeEvent struct {
+	EventMessage
+	EventKey     string       `xml:"EventKey"`
+	ScanCodeInfo ScanCodeInfo `xml:"ScanCodeInfo"`
+}
+
+/*PicEvent 弹出发图器事件(pic_sysphoto,pic_photo_or_album,pic_weixin) */
+type PicEvent struct {
+	EventMessage
+	EventKey     string       `xml:"EventKey"`
+	SendPicsInfo SendPicsInfo `xml:"SendPicsInfo"`
+}
+
+/*LocationSelectEvent 弹出地理位置选择器事件 */
+type LocationSelectEvent struct {
+	EventMessage
+	EventKey         string           `xml:"EventKey"`
+	SendLocationInfo SendLocationInfo `xml:"SendLocationInfo"`
+}
+
+/*TemplateSendJobFinishEvent 模板消息发送结果 */
+type TemplateSendJobFinishEvent struct {
+	EventMessage
+	MsgID  int64  `xml:"MsgID"`
+	Status string `xml:"Status"` //success,failed:user block,failed: system failed
+}
+
+/*CopyrightCheckResultItem 单篇文章原创校验结果 */
+type CopyrightCheckResultItem struct {
+	ArticleIdx            int    `xml:"ArticleIdx"`
+	UserDeclareState      int    `xml:"UserDeclareState"`
+	AuditState            int    `xml:"AuditState"`
+	OriginalArticleURL    string `xml:"OriginalArticleUrl"`
+	OriginalArticleType   int    `xml:"OriginalArticleType"`
+	CanReprint            int    `xml:"CanReprint"`
+	NeedReplaceContent    int    `xml:"NeedReplaceContent"`
+	NeedShowReprintSource int    `xml:"NeedShowReprintSource"`
+}
+
+/*CopyrightCheckResult 群发原创校验结果 */
+type CopyrightCheckResult struct {
+	Count      int                        `xml:"Count"`
+	ResultList []CopyrightCheckResultItem `xml:"ResultList>item"`
+	CheckState int                        `xml:"CheckState"` //1-未被判为转载,可以群发;2-被判为转载,可以群发;3-被判为转载,不能群发
+}
+
+/*ArticleURLResultItem 群发文章链接 */
+type ArticleURLResultItem struct {
+	ArticleIdx int    `xml:"ArticleIdx"`
+	ArticleURL string `xml:"ArticleUrl"`
+}
+
+/*ArticleURLResult 群发文章链接列表 */
+type ArticleURLResult struct {
+	Count      int                    `xml:"Count"`
+	ResultList []ArticleURLResultItem `xml:"ResultList>item"`
+}
+
+/*MassSendJobFinishEvent 群发结果通知 */
+type MassSendJobFinishEvent struct {
+	EventMessage
+	MsgID                int64                `xml:"MsgID"`
+	Status               string               `xml:"Status"`
+	TotalCount           int                  `xml:"TotalCount"`
+	FilterCount          int                  `xml:"FilterCount"`
+	SentCount            int                  `xml:"SentCount"`
+	ErrorCount           int                  `xml:"ErrorCount"`
+	CopyrightCheckResult CopyrightCheckResult `xml:"CopyrightCheckResult"`
+	ArticleURLResult     ArticleURLResult     `xml:"ArticleUrlResult"`
+}
+
+/*WifiConnectedEvent 连网后下发消息 */
+type WifiConnectedEvent struct {
+	EventMessage
+	ConnectTime int64  `xml:"ConnectTime"`
+	ExpireTime  int64  `xml:"ExpireTime"`
+	VendorID    string `xml:"VendorId"`
+	ShopID      string `xml:"ShopId"`
+	DeviceNo    string `xml:"DeviceNo"`
+}
+
+/*CardCheckEvent 卡券审核事件(card_pass_check,card_not_pass_check) */
+type CardCheckEvent struct {
+	EventMessage
+	CardID       string `xml:"CardId"`
+	RefuseReason string `xml:"RefuseReason"`
+}
+
+/*UserGetCardEvent 领取卡券事件 */
+type UserGetCardEvent struct {
+	EventMessage
+	CardID              string `xml:"CardId"`
+	IsGiveByFriend      int    `xml:"IsGiveByFriend"`
+	UserCardCode        string `xml:"UserCardCode"`
+	FriendUserName      string `xml:"FriendUserName"`
+	OuterID             int    `xml:"OuterId"`
+	OldUserCardCode     string `xml:"OldUserCardCode"`
+	OuterStr            string `xml:"OuterStr"`
+	IsRestoreMemberCard int    `xml:"IsRestoreMemberCard"`
+	IsRecommendByFriend int    `xml:"IsRecommendByFriend"`
+	UnionID             string `xml:"UnionId"`
+}
+
+/*UserGiftingCardEvent 转赠卡券事件 */
+type UserGiftingCardEvent struct {
+	EventMessage
+	CardID         string `xml:"CardId"`
+	UserCardCode   string `xml:"UserCardCode"`
+	IsReturnBack   int    `xml:"IsReturnBack"`
+	FriendUserName string `xml:"FriendUserName"`
+	IsChatRoom     int    `xml:"IsChatRoom"`
+}
+
+/*UserDelCardEvent 删除卡券事件 */
+type UserDelCardEvent struct {
+	EventMessage
+	CardID       string `xml:"CardId"`
+	UserCardCode string `xml:"UserCardCode"`
+}
+
+/*UserConsumeCardEvent 核销卡券事件 */
+type UserConsumeCardEvent struct {
+	EventMessage
+	CardID        string `xml:"CardId"`
+	UserCardCode  string `xml:"UserCardCode"`
+	ConsumeSource string `xml:"ConsumeSource"`
+	LocationName  string `xml:"LocationName"`
+	StaffOpenID   string `xml:"StaffOpenId"`
+	VerifyCode    string `xml:"VerifyCode"`
+	RemarkAmount  string `xml:"RemarkAmount"`
+	OuterStr      string `xml:"OuterStr"`
+}
+
+/*UserPayFromPayCellEvent 买单事件 */
+type UserPayFromPayCellEvent struct {
+	EventMessage
+	CardID       string `xml:"CardId"`
+	UserCardCode string `xml:"UserCardCode"`
+	TransID      string `xml:"TransId"`
+	LocationID   int64  `xml:"LocationId"`
+	Fee          int    `xml:"Fee"`
+	OriginalFee  int    `xml:"OriginalFee"`
+}
+
+/*UserViewCardEvent 进入会员卡事件 */
+type UserViewCardEvent struct {
+	EventMessage
+	CardID       string `xml:"CardId"`
+	UserCardCode string `xml:"UserCardCode"`
+	OuterStr     string `xml:"OuterStr"`
+}
+
+/*UserEnterSessionFromCardEvent 从卡券进入公众号会话事件 */
+type UserEnterSessionFromCardEvent struct {
+	EventMessage
+	CardID       string `xml:"CardId"`
+	UserCardCode string `xml:"UserCardCode"`
+}
+
+/*UpdateMemberCardEvent 会员卡内容更新事件 */
+type UpdateMemberCardEvent struct {
+	EventMessage
+	CardID        string `xml:"CardId"`
+	UserCardCode  string `xml:"UserCardCode"`
+	ModifyBonus   int    `xml:"ModifyBonus"`
+	ModifyBalance int    `xml:"ModifyBalance"`
+}
+
+/*CardSkuRemindEvent 库存报警事件 */
+type CardSkuRemindEvent struct {
+	EventMessage
+	CardID string `xml:"CardId"`
+	Detail string `xml:"Detail"`
+}
+
+/*SubmitMemberCardUserInfoEvent 会员卡激活事件 */
+type SubmitMemberCardUserInfoEvent struct {
+	EventMessage
+	CardID       string `xml:"CardId"`
+	UserCardCode string `xml:"UserCardCode"`
+}
+
+/*SubscribeMsgItem 订阅通知事件中的模板 */
+type SubscribeMsgItem struct {
+	TemplateID            string `xml:"TemplateId"`
+	SubscribeStatusString string `xml:"SubscribeStatusString"` //accept,reject
+	PopupScene            string `xml:"PopupScene"`            //0-h5,1-发消息,2-支付完成
+	MsgID                 string `xml:"MsgID"`
+	ErrorCode             string `xml:"ErrorCode"`
+	ErrorStatus           string `xml:"ErrorStatus"`
+}
+
+/*SubscribeMsgPopupEvent 用户操作订阅通知弹窗 */
+type SubscribeMsgPopupEvent struct {
+	EventMessage
+	List []SubscribeMsgItem `xml:"SubscribeMsgPopupEvent>List"`
+}
+
+/*SubscribeMsgChangeEvent 用户管理订阅通知 */
+type SubscribeMsgChangeEvent struct {
+	EventMessage
+	List []SubscribeMsgItem `xml:"SubscribeMsgChangeEvent>List"`
+}
+
+/*SubscribeMsgSentEvent 发送订阅通知结果 */
+type SubscribeMsgSentEvent struct {
+	EventMessage
+	List []SubscribeMsgItem `xml:"SubscribeMsgSentEvent>List"`
+}
+
+/*RiskSuggest 内容安全检测建议 */
+type RiskSuggest string
+
+/*RiskSuggest types */
+const (
+	RiskSuggestRisky  RiskSuggest = "risky"  //违规
+	RiskSuggestPass   RiskSuggest = "pass"   //通过
+	RiskSuggestReview RiskSuggest = "review" //建议人工复审
+)
+
+/*RiskLabel 内容安全命中标签 */
+type RiskLabel int
+
+/*RiskLabel types */
+const (
+	RiskLabelNormal    RiskLabel = 100   //正常
+	RiskLabelAd        RiskLabel = 10001 //广告
+	RiskLabelPolitics  RiskLabel = 20001 //时政
+	RiskLabelPorn      RiskLabel = 20002 //色情
+	RiskLabelAbuse     RiskLabel = 20003 //辱骂
+	RiskLabelCrime     RiskLabel = 20006 //违法犯罪
+	RiskLabelFraud     RiskLabel = 20008 //欺诈
+	RiskLabelVulgar    RiskLabel = 20012 //低俗
+	RiskLabelCopyright RiskLabel = 20013 //版权
+	RiskLabelOther     RiskLabel = 21000 //其他
+)
+
+/*RiskResult 内容安全综合结果 */
+type RiskResult struct {
+	Suggest RiskSuggest `xml:"suggest" json:"suggest"`
+	Label   RiskLabel   `xml:"label" json:"label"`
+}
+
+/*RiskDetail 内容安全各检测策略结果 */
+type RiskDetail struct {
+	Strategy string      `xml:"strategy" json:"strategy"`
+	ErrCode  int         `xml:"errcode" json:"errcode"`
+	Suggest  RiskSuggest `xml:"suggest" json:"suggest"`
+	Label    RiskLabel   `xml:"label" json:"label"`
+	Prob     int         `xml:"prob" json:"prob"`
+	Keyword  string      `xml:"keyword" json:"keyword"`
+}
+
+/*WxaMediaCheckEvent 音视频内容安全异步检测结果 */
+type WxaMediaCheckEvent struct {
+	EventMessage
+	AppID         string       `xml:"appid" json:"appid"`
+	TraceID       string       `xml:"trace_id" json:"trace_id"`
+	Version       int          `xml:"version" json:"version"`
+	StatusCode    int          `xml:"status_code" json:"status_code"`
+	IsRisky       int          `xml:"isrisky" json:"isrisky"` //仅version 1
+	ExtraInfoJSON string       `xml:"extra_info_json" json:"extra_info_json"`
+	Detail        []RiskDetail `xml:"detail" json:"detail"`
+	Result        RiskResult   `xml:"result" json:"result"`
+}
+
+/*Risky 检测结果是否违规 */
+func (e *WxaMediaCheckEvent) Risky() bool {
+	if e.Version < 2 {
+		return e.IsRisky == 1
+	}
+	return e.Result.Suggest == RiskSuggestRisky
+}
+
+/*WeappAuditEvent 第三方平台代为提交的小程序代码审核结果 */
+type WeappAuditEvent struct {
+	EventMessage
+	SuccTime   int64  `xml:"SuccTime"`
+	FailTime   int64  `xml:"FailTime"`
+	DelayTime  int64  `xml:"DelayTime"`
+	Reason     string `xml:"Reason"`
+	ScreenShot string `xml:"ScreenShot"` //审核不通过的截图示例,用|分隔的media_id列表
+}
+
+/*ExpressPathAction 运单轨迹节点 */
+type ExpressPathAction struct {
+	ActionTime int64  `xml:"ActionTime" json:"ActionTime"`
+	ActionType int    `xml:"ActionType" json:"ActionType"` //100001揽件,200001运输中,300002派件中,300003已签收,300004投递失败
+	ActionMsg  string `xml:"ActionMsg" json:"ActionMsg"`
+}
+
+/*AddExpressPathEvent 物流助手运单轨迹更新 */
+type AddExpressPathEvent struct {
+	EventMessage
+	DeliveryID string               `xml:"DeliveryID" json:"DeliveryID"`
+	WayBillID  string               `xml:"WayBillId" json:"WayBillId"`
+	OrderID    string               `xml:"OrderId" json:"OrderId"`
+	Version    int                  `xml:"Version" json:"Version"`
+	Count      int                  `xml:"Count" json:"Count"`
+	Actions    []*ExpressPathAction `xml:"Actions" json:"Actions"`
+}
+
+var events = struct {
+	sync.RWMutex
+	types map[string]func() Eventer
+}{
+	types: map[string]func() Eventer{},
+}
+
+func init() {
+	eventLists := map[EventType]func() Eventer{
+		EventTypeSubscribe:                func() Eventer { return new(SubscribeEvent) },
+		EventTypeUnsubscribe:              func() Eventer { return new(UnsubscribeEvent) },
+		EventTypeScan:                     func() Eventer { return new(ScanEvent) },
+		EventTypeLocation:                 func() Eventer { return new(LocationEvent) },
+		EventTypeClick:                    func() Eventer { return new(ClickEvent) },
+		EventTypeView:                     func() Eventer { return new(ViewEvent) },
+		EventTypeViewMiniprogram:          func() Eventer { return new(ViewMiniprogramEvent) },
+		EventTypeScancodePush:             func() Eventer { return new(ScanCodeEvent) },
+		EventTypeScancodeWaitmsg:          func() Eventer { return new(ScanCodeEvent) },
+		EventTypePicSysphoto:              func() Eventer { return new(PicEvent) },
+		EventTypePicPhotoOrAlbum:          func() Eventer { return new(PicEvent) },
+		EventTypePicWeixin:                func() Eventer { return new(PicEvent) },
+		EventTypeLocationSelect:           func() Eventer { return new(LocationSelectEvent) },
+		EventTypeTemplateSendJobFinish:    func() Eventer { return new(TemplateSendJobFinishEvent) },
+		EventTypeMassSendJobFinish:        func() Eventer { return new(MassSendJobFinishEvent) },
+		EventTypeWifiConnected:            func() Eventer { return new(WifiConnectedEvent) },
+		EventTypeCardPassCheck:            func() Eventer { return new(CardCheckEvent) },
+		EventTypeCardNotPassCheck:         func() Eventer { return new(CardCheckEvent) },
+		EventTypeUserGetCard:              func() Eventer { return new(UserGetCardEvent) },
+		EventTypeUserGiftingCard:          func() Eventer { return new(UserGiftingCardEvent) },
+		EventTypeUserDelCard:              func() Eventer { return new(UserDelCardEvent) },
+		EventTypeUserConsumeCard:          func() Eventer { return new(UserConsumeCardEvent) },
+		EventTypeUserPayFromPayCell:       func() Eventer { return new(UserPayFromPayCellEvent) },
+		EventTypeUserViewCard:             func() Eventer { return new(UserViewCardEvent) },
+		EventTypeUserEnterSessionFromCard: func() Eventer { return new(UserEnterSessionFromCardEvent) },
+		EventTypeUpdateMemberCard:         func() Eventer { return new(UpdateMemberCardEvent) },
+		EventTypeCardSkuRemind:            func() Eventer { return new(CardSkuRemindEvent) },
+		EventTypeSubmitMemberCardUserInfo: func() Eventer { return new(SubmitMemberCardUserInfoEvent) },
+		EventTypeSubscribeMsgPopup:        func() Eventer { return new(SubscribeMsgPopupEvent) },
+		EventTypeSubscribeMsgChange:       func() Eventer { return new(SubscribeMsgChangeEvent) },
+		EventTypeSubscribeMsgSent:         func() Eventer { return new(SubscribeMsgSentEvent) },
+		EventTypeWxaMediaCheck:            func() Eventer { return new(WxaMediaCheckEvent) },
+		EventTypeWeappAuditSuccess:        func() Eventer { return new(WeappAuditEvent) },
+		EventTypeWeappAuditFail:           func() Eventer { return new(WeappAuditEvent) },
+		EventTypeWeappAuditDelay:          func() Eventer { return new(WeappAuditEvent) },
+		EventTypeAddExpressPath:           func() Eventer { return new(AddExpressPathEvent) },
+	}
+	for k, v := range eventLists {
+		RegisterEvent(k, v)
+	}
+}
+
+/*RegisterEvent 注册事件类型对应的结构,事件名不区分大小写 */
+func RegisterEvent(evtType EventType, fn func() Eventer) {
+	events.Lock()
+	defer events.Unlock()
+	events.types[strings.ToLower(evtType.String())] = fn
+}
+
+/*DecodeEvent 根据MsgType及Event将事件推送解析为对应的结构,未注册的事件返回*EventMessage */
+func DecodeEvent(data []byte) (Eventer, error) {
+	return decodeEvent(data, xml.Unmarshal)
+}
+
+/*DecodeEventJSON 解析json格式的事件推送 */
+func DecodeEventJSON(data []byte) (Eventer, error) {
+	return decodeEvent(data, json.Unmarshal)
+}
+
+func decodeEvent(data []byte, unmarshal func([]byte, interface{}) error) (Eventer, error) {
+	var msg EventMessage
+	if err := unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Message.Compare(MsgTypeEvent) != 0 {
+		return nil, ErrNotEvent
+	}
+
+	events.RLock()
+	fn, b := events.types[strings.ToLower(msg.GetEvent().String())]
+	events.RUnlock()
+	if !b {
+		return &msg, nil
+	}
+	evt := fn()
+	if err := unmarshal(data, evt); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
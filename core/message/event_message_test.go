@@ -0,0 +1,60 @@
+package message_test
+
+import (
+	"testing"
+
+	"github.com/godcong/wego/core/message"
+)
+
+// TestDecodeEvent ...
+func TestDecodeEvent(t *testing.T) {
+	mass := `<xml><ToUserName><![CDATA[gh_4d00ed8d6399]]></ToUserName><FromUserName><![CDATA[oV5CrjpxgaGXNHIQigzNlgLTnwic]]></FromUserName><CreateTime>1481013459</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[MASSSENDJOBFINISH]]></Event><MsgID>1000001625</MsgID><Status><![CDATA[err(30003)]]></Status><TotalCount>0</TotalCount><FilterCount>0</FilterCount><SentCount>0</SentCount><ErrorCount>0</ErrorCount><CopyrightCheckResult><Count>2</Count><ResultList><item><ArticleIdx>1</ArticleIdx><UserDeclareState>0</UserDeclareState><AuditState>2</AuditState><OriginalArticleUrl><![CDATA[Url_1]]></OriginalArticleUrl><OriginalArticleType>1</OriginalArticleType><CanReprint>1</CanReprint><NeedReplaceContent>1</NeedReplaceContent><NeedShowReprintSource>1</NeedShowReprintSource></item><item><ArticleIdx>2</ArticleIdx></item></ResultList><CheckState>2</CheckState></CopyrightCheckResult></xml>`
+	evt, err := message.DecodeEvent([]byte(mass))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, b := evt.(*message.MassSendJobFinishEvent)
+	if !b || m.MsgID != 1000001625 || m.CopyrightCheckResult.CheckState != 2 ||
+		len(m.CopyrightCheckResult.ResultList) != 2 || m.CopyrightCheckResult.ResultList[0].OriginalArticleURL != "Url_1" ||
+		m.FromUserName.Value != "oV5CrjpxgaGXNHIQigzNlgLTnwic" {
+		t.Errorf("%+v", evt)
+	}
+
+	card := `<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[user_consume_card]]></Event><CardId><![CDATA[cardid]]></CardId><UserCardCode><![CDATA[12312312]]></UserCardCode><ConsumeSource><![CDATA[FROM_API]]></ConsumeSource><StaffOpenId><![CDATA[oFS7Fjl0WsZ9AMZqrI80nbIq8xrA]]></StaffOpenId></xml>`
+	evt, err = message.DecodeEvent([]byte(card))
+	if c, b := evt.(*message.UserConsumeCardEvent); err != nil || !b || c.CardID != "cardid" || c.ConsumeSource != "FROM_API" {
+		t.Errorf("%+v %v", evt, err)
+	}
+
+	popup := `<xml><ToUserName><![CDATA[gh_123456789abc]]></ToUserName><FromUserName><![CDATA[otFpruAK8D-E6EfStSYonYSBZ8_4]]></FromUserName><CreateTime>1610969440</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[subscribe_msg_popup_event]]></Event><SubscribeMsgPopupEvent><List><TemplateId><![CDATA[VRR0UEO9VJOLs0MHlU0OilqX6MVFDwH3_3gz3Oc0NIc]]></TemplateId><SubscribeStatusString><![CDATA[accept]]></SubscribeStatusString><PopupScene>2</PopupScene></List><List><TemplateId><![CDATA[9nLIlbOQZC5Y89AZteFEux3WCXRRRG5Wfzkpssu4bLI]]></TemplateId><SubscribeStatusString><![CDATA[reject]]></SubscribeStatusString><PopupScene>2</PopupScene></List></SubscribeMsgPopupEvent></xml>`
+	evt, err = message.DecodeEvent([]byte(popup))
+	if p, b := evt.(*message.SubscribeMsgPopupEvent); err != nil || !b || len(p.List) != 2 || p.List[1].SubscribeStatusString != "reject" {
+		t.Errorf("%+v %v", evt, err)
+	}
+
+	audit := `<xml><ToUserName><![CDATA[gh_fb9688c2a4b2]]></ToUserName><FromUserName><![CDATA[od1P50M-fNQI5Gcq-trm4a7apsU8]]></FromUserName><CreateTime>1488856591</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[weapp_audit_fail]]></Event><Reason><![CDATA[1:账号信息不符合规范]]></Reason><FailTime>1488856591</FailTime><ScreenShot><![CDATA[xxx|yyy|zzz]]></ScreenShot></xml>`
+	evt, err = message.DecodeEvent([]byte(audit))
+	if a, b := evt.(*message.WeappAuditEvent); err != nil || !b || a.FailTime != 1488856591 || a.ScreenShot != "xxx|yyy|zzz" || a.GetEvent() != message.EventTypeWeappAuditFail {
+		t.Errorf("%+v %v", evt, err)
+	}
+
+	unknown := `<xml><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[unknown_event]]></Event></xml>`
+	evt, err = message.DecodeEvent([]byte(unknown))
+	if e, b := evt.(*message.EventMessage); err != nil || !b || e.GetEvent() != "unknown_event" {
+		t.Errorf("%+v %v", evt, err)
+	}
+
+	if _, err = message.DecodeEvent([]byte(`<xml><MsgType><![CDATA[text]]></MsgType></xml>`)); err != message.ErrNotEvent {
+		t.Error(err)
+	}
+}
+
+// TestDecodeEventJSON ...
+func TestDecodeEventJSON(t *testing.T) {
+	popup := `{"ToUserName":"gh_123456789abc","FromUserName":"o7esq5OI1Uej6Xixw1lA2H7XDVbc","CreateTime":1620973045,"MsgType":"event","Event":"subscribe_msg_popup_event","List":[{"TemplateId":"hD-ixGOhYmUfjOnI8MCzQMPshzGVeux_2vBgDhmBmmk","SubscribeStatusString":"accept","PopupScene":"0"}]}`
+	evt, err := message.DecodeEventJSON([]byte(popup))
+	if p, b := evt.(*message.SubscribeMsgPopupEvent); err != nil || !b || len(p.List) != 1 ||
+		p.List[0].SubscribeStatusString != "accept" || p.FromUserName.Value != "o7esq5OI1Uej6Xixw1lA2H7XDVbc" {
+		t.Errorf("%+v %v", evt, err)
+	}
+}
@@ -1,6 +1,7 @@
 package message
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"strings"
 
@@ -41,14 +42,29 @@ type MSGCDATA struct {
 	MsgType `xml:",cdata"`
 }
 
+/*MarshalJSON json格式回复中MsgType输出为普通字符串 */
+func (m MSGCDATA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(m.MsgType))
+}
+
+/*UnmarshalJSON 解析json格式推送中的MsgType */
+func (m *MSGCDATA) UnmarshalJSON(b []byte) error {
+	var v CDATA
+	if err := v.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	m.MsgType = MsgType(v.Value)
+	return nil
+}
+
 /*Message Message */
 type Message struct {
 	XMLName      xml.Name `xml:"xml"`
 	MsgType      MSGCDATA `xml:"MsgType"`
-	MsgID        int64    `xml:"MsgID,omitempty"`
-	ToUserName   CDATA    `xml:"to_user_name"`
-	FromUserName CDATA    `xml:"from_user_name"`
-	CreateTime   int64    `xml:"create_time"`
+	MsgID        int64    `xml:"MsgId,omitempty"`
+	ToUserName   CDATA    `xml:"ToUserName"`
+	FromUserName CDATA    `xml:"FromUserName"`
+	CreateTime   int64    `xml:"CreateTime"`
 }
 
 /*String String */
@@ -0,0 +1,249 @@
+package message
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"time"
+
+	"github.com/godcong/wego/util"
+)
+
+// MaxReplyArticles 被动回复图文消息最多条数
+const MaxReplyArticles = 8
+
+// ErrEmptyContent 文本内容为空
+var ErrEmptyContent = errors.New("reply content is empty")
+
+// ErrEmptyMediaID 缺少media_id
+var ErrEmptyMediaID = errors.New("reply media id is empty")
+
+// ErrEmptyThumbMediaID 缺少thumb_media_id
+var ErrEmptyThumbMediaID = errors.New("reply thumb media id is empty")
+
+// ErrArticleCount 图文数量错误
+var ErrArticleCount = errors.New("reply article count must be between 1 and 8")
+
+// ErrNilArticle 图文为空
+var ErrNilArticle = errors.New("reply article is nil")
+
+/*ReplyMedia 图片,语音,视频回复内容 */
+type ReplyMedia struct {
+	MediaID     string `xml:"MediaId" json:"MediaId"`
+	Title       string `xml:"Title,omitempty" json:"Title,omitempty"`
+	Description string `xml:"Description,omitempty" json:"Description,omitempty"`
+}
+
+/*ReplyMusic 音乐回复内容 */
+type ReplyMusic struct {
+	Title        string `xml:"Title,omitempty" json:"Title,omitempty"`
+	Description  string `xml:"Description,omitempty" json:"Description,omitempty"`
+	MusicURL     string `xml:"MusicUrl,omitempty" json:"MusicUrl,omitempty"`
+	HQMusicURL   string `xml:"HQMusicUrl,omitempty" json:"HQMusicUrl,omitempty"`
+	ThumbMediaID string `xml:"ThumbMediaId" json:"ThumbMediaId"`
+}
+
+/*ReplyArticle 图文回复内容 */
+type ReplyArticle struct {
+	Title       string `xml:"Title" json:"Title"`
+	Description string `xml:"Description" json:"Description"`
+	PicURL      string `xml:"PicUrl" json:"PicUrl"`
+	URL         string `xml:"Url" json:"Url"`
+}
+
+/*ReplyArticles 图文回复列表 */
+type ReplyArticles struct {
+	Items []*ReplyArticle `xml:"item" json:"item"`
+}
+
+/*ReplyTransInfo 指定转发的客服帐号 */
+type ReplyTransInfo struct {
+	KfAccount string `xml:"KfAccount" json:"KfAccount"`
+}
+
+/*Reply 被动回复消息,由收到的消息生成并交换ToUserName及FromUserName
+json格式与xml格式字段相同,用于小程序json格式推送的回复
+*/
+type Reply struct {
+	XMLName      xml.Name        `xml:"xml" json:"-"`
+	ToUserName   CDATA           `xml:"ToUserName" json:"ToUserName"`
+	FromUserName CDATA           `xml:"FromUserName" json:"FromUserName"`
+	CreateTime   int64           `xml:"CreateTime" json:"CreateTime"`
+	MsgType      MSGCDATA        `xml:"MsgType" json:"MsgType"`
+	Content      *CDATA          `xml:"Content,omitempty" json:"Content,omitempty"`
+	Image        *ReplyMedia     `xml:"Image,omitempty" json:"Image,omitempty"`
+	Voice        *ReplyMedia     `xml:"Voice,omitempty" json:"Voice,omitempty"`
+	Video        *ReplyMedia     `xml:"Video,omitempty" json:"Video,omitempty"`
+	Music        *ReplyMusic     `xml:"Music,omitempty" json:"Music,omitempty"`
+	ArticleCount int             `xml:"ArticleCount,omitempty" json:"ArticleCount,omitempty"`
+	Articles     *ReplyArticles  `xml:"Articles,omitempty" json:"Articles,omitempty"`
+	TransInfo    *ReplyTransInfo `xml:"TransInfo,omitempty" json:"TransInfo,omitempty"`
+}
+
+/*NewReply 根据收到的消息创建回复 */
+func NewReply(msg *Message, msgType MsgType) *Reply {
+	return &Reply{
+		ToUserName:   CDATA{Value: msg.FromUserName.Value},
+		FromUserName: CDATA{Value: msg.ToUserName.Value},
+		CreateTime:   time.Now().Unix(),
+		MsgType:      MSGCDATA{MsgType: msgType},
+	}
+}
+
+/*NewTextReply 回复文本消息 */
+func NewTextReply(msg *Message, content string) *Reply {
+	r := NewReply(msg, MsgTypeText)
+	r.Content = &CDATA{Value: content}
+	return r
+}
+
+/*NewImageReply 回复图片消息 */
+func NewImageReply(msg *Message, mediaID string) *Reply {
+	r := NewReply(msg, MsgTypeImage)
+	r.Image = &ReplyMedia{MediaID: mediaID}
+	return r
+}
+
+/*NewVoiceReply 回复语音消息 */
+func NewVoiceReply(msg *Message, mediaID string) *Reply {
+	r := NewReply(msg, MsgTypeVoice)
+	r.Voice = &ReplyMedia{MediaID: mediaID}
+	return r
+}
+
+/*NewVideoReply 回复视频消息 */
+func NewVideoReply(msg *Message, mediaID, title, description string) *Reply {
+	r := NewReply(msg, MsgTypeVideo)
+	r.Video = &ReplyMedia{
+		MediaID:     mediaID,
+		Title:       title,
+		Description: description,
+	}
+	return r
+}
+
+/*NewMusicReply 回复音乐消息 */
+func NewMusicReply(msg *Message, music *ReplyMusic) *Reply {
+	r := NewReply(msg, MsgTypeMusic)
+	r.Music = music
+	return r
+}
+
+/*NewNewsReply 回复图文消息 */
+func NewNewsReply(msg *Message, articles ...*ReplyArticle) *Reply {
+	r := NewReply(msg, MsgTypeNews)
+	r.ArticleCount = len(articles)
+	r.Articles = &ReplyArticles{Items: articles}
+	return r
+}
+
+/*NewTransferReply 将消息转发到客服,kfAccount为空时由系统分配 */
+func NewTransferReply(msg *Message, kfAccount string) *Reply {
+	r := NewReply(msg, MsgTypeTransfer)
+	if kfAccount != "" {
+		r.TransInfo = &ReplyTransInfo{KfAccount: kfAccount}
+	}
+	return r
+}
+
+/*Validate 校验回复内容 */
+func (r *Reply) Validate() error {
+	switch r.MsgType.MsgType {
+	case MsgTypeText:
+		if r.Content == nil || r.Content.Value == "" {
+			return ErrEmptyContent
+		}
+	case MsgTypeImage:
+		return validateMedia(r.Image)
+	case MsgTypeVoice:
+		return validateMedia(r.Voice)
+	case MsgTypeVideo:
+		return validateMedia(r.Video)
+	case MsgTypeMusic:
+		if r.Music == nil || r.Music.ThumbMediaID == "" {
+			return ErrEmptyThumbMediaID
+		}
+	case MsgTypeNews:
+		if r.Articles == nil || len(r.Articles.Items) == 0 || len(r.Articles.Items) > MaxReplyArticles {
+			return ErrArticleCount
+		}
+		for _, item := range r.Articles.Items {
+			if item == nil {
+				return ErrNilArticle
+			}
+		}
+	}
+	return nil
+}
+
+func validateMedia(media *ReplyMedia) error {
+	if media == nil || media.MediaID == "" {
+		return ErrEmptyMediaID
+	}
+	return nil
+}
+
+/*ToXML 转换为被动回复xml */
+func (r *Reply) ToXML() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return xml.Marshal(r)
+}
+
+/*ToJSON 转换为被动回复json */
+func (r *Reply) ToJSON() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r)
+}
+
+/*ToCustomMessage 转换为客服消息格式,用于超时后通过客服消息接口异步发送
+转发客服(transfer_customer_service)仅限被动回复,不能作为客服消息发送
+*/
+func (r *Reply) ToCustomMessage() util.Map {
+	msgType := r.MsgType.String()
+	m := util.Map{
+		"touser":  r.ToUserName.Value,
+		"msgtype": msgType,
+	}
+	switch r.MsgType.MsgType {
+	case MsgTypeText:
+		m.Set(msgType, util.Map{"content": r.Content.Value})
+	case MsgTypeImage:
+		m.Set(msgType, util.Map{"media_id": r.Image.MediaID})
+	case MsgTypeVoice:
+		m.Set(msgType, util.Map{"media_id": r.Voice.MediaID})
+	case MsgTypeVideo:
+		m.Set(msgType, util.Map{
+			"media_id":    r.Video.MediaID,
+			"title":       r.Video.Title,
+			"description": r.Video.Description,
+		})
+	case MsgTypeMusic:
+		m.Set(msgType, util.Map{
+			"title":          r.Music.Title,
+			"description":    r.Music.Description,
+			"musicurl":       r.Music.MusicURL,
+			"hqmusicurl":     r.Music.HQMusicURL,
+			"thumb_media_id": r.Music.ThumbMediaID,
+		})
+	case MsgTypeNews:
+		var articles []util.Map
+		for _, v := range r.Articles.Items {
+			articles = append(articles, util.Map{
+				"title":       v.Title,
+				"description": v.Description,
+				"url":         v.URL,
+				"picurl":      v.PicURL,
+			})
+		}
+		m.Set(msgType, util.Map{"articles": articles})
+	case MsgTypeTransfer:
+		if r.TransInfo != nil {
+			m.Set("transinfo", util.Map{"kfaccount": r.TransInfo.KfAccount})
+		}
+	}
+	return m
+}
@@ -0,0 +1,104 @@
+package message_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/godcong/wego/core/message"
+)
+
+var incoming = message.New(message.MsgTypeText, "gh_56870ffd193b", "oLyBi0hSYhggnD-kOIms0IzZFqrc", 6547288321577417974, 1524409354)
+
+// TestNewTextReply ...
+func TestNewTextReply(t *testing.T) {
+	b, err := message.NewTextReply(incoming, "hello").ToXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rlt struct {
+		ToUserName   string
+		FromUserName string
+		MsgType      string
+		Content      string
+	}
+	if err = xml.Unmarshal(b, &rlt); err != nil {
+		t.Fatal(err)
+	}
+	if rlt.ToUserName != "oLyBi0hSYhggnD-kOIms0IzZFqrc" || rlt.FromUserName != "gh_56870ffd193b" ||
+		rlt.MsgType != "text" || rlt.Content != "hello" || strings.Contains(string(b), "Articles") {
+		t.Error(string(b))
+	}
+
+	if _, err = message.NewTextReply(incoming, "").ToXML(); err != message.ErrEmptyContent {
+		t.Error(err)
+	}
+}
+
+// TestReply_Validate ...
+func TestReply_Validate(t *testing.T) {
+	if err := message.NewImageReply(incoming, "").Validate(); err != message.ErrEmptyMediaID {
+		t.Error(err)
+	}
+	if err := message.NewMusicReply(incoming, &message.ReplyMusic{Title: "music"}).Validate(); err != message.ErrEmptyThumbMediaID {
+		t.Error(err)
+	}
+	if err := message.NewNewsReply(incoming).Validate(); err != message.ErrArticleCount {
+		t.Error(err)
+	}
+	articles := make([]*message.ReplyArticle, message.MaxReplyArticles+1)
+	if err := message.NewNewsReply(incoming, articles...).Validate(); err != message.ErrArticleCount {
+		t.Error(err)
+	}
+	if err := message.NewNewsReply(incoming, &message.ReplyArticle{Title: "title"}, nil).Validate(); err != message.ErrNilArticle {
+		t.Error(err)
+	}
+}
+
+// TestNewNewsReply ...
+func TestNewNewsReply(t *testing.T) {
+	reply := message.NewNewsReply(incoming, &message.ReplyArticle{Title: "title", URL: "https://github.com/godcong/wego"})
+	b, err := reply.ToXML()
+	if err != nil || !strings.Contains(string(b), "<ArticleCount>1</ArticleCount><Articles><item><Title>title</Title>") {
+		t.Error(string(b), err)
+	}
+	custom := reply.ToCustomMessage()
+	if b := custom.ToJSON(); !strings.Contains(string(b), `"articles":[{`) || custom.GetString("touser") != "oLyBi0hSYhggnD-kOIms0IzZFqrc" {
+		t.Error(string(b))
+	}
+}
+
+// TestReply_ToJSON ...
+func TestReply_ToJSON(t *testing.T) {
+	b, err := message.NewImageReply(incoming, "media_id").ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rlt struct {
+		ToUserName string
+		MsgType    string
+		CreateTime int64
+		Image      struct {
+			MediaID string `json:"MediaId"`
+		}
+	}
+	if err = json.Unmarshal(b, &rlt); err != nil {
+		t.Fatal(err)
+	}
+	if rlt.ToUserName != "oLyBi0hSYhggnD-kOIms0IzZFqrc" || rlt.MsgType != "image" || rlt.CreateTime == 0 || rlt.Image.MediaID != "media_id" {
+		t.Error(string(b))
+	}
+}
+
+// TestNewTransferReply ...
+func TestNewTransferReply(t *testing.T) {
+	b, err := message.NewTransferReply(incoming, "test1@test").ToXML()
+	if err != nil || !strings.Contains(string(b), "<TransInfo><KfAccount>test1@test</KfAccount></TransInfo>") {
+		t.Error(string(b), err)
+	}
+	b, _ = message.NewTransferReply(incoming, "").ToXML()
+	if strings.Contains(string(b), "TransInfo") {
+		t.Error(string(b))
+	}
+}
@@ -2,6 +2,6 @@ package message
 
 /*ScanCodeInfo ScanCodeInfo */
 type ScanCodeInfo struct {
-	ScanType   CDATA `xml:"scan_type"`
-	ScanResult CDATA `xml:"scan_result"`
+	ScanType   CDATA `xml:"ScanType"`
+	ScanResult CDATA `xml:"ScanResult"`
 }
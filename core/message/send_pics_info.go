@@ -7,6 +7,6 @@ type PicList struct {
 
 /*SendPicsInfo SendPicsInfo */
 type SendPicsInfo struct {
-	Count   int       `xml:"count"` //发送的图片数量
+	Count   int       `xml:"Count"` //发送的图片数量
 	PicList []PicList `xml:"PicList>item"`
 }
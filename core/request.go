@@ -199,10 +199,17 @@ func processNothing(method, url string, i interface{}) *http.Request {
 func processMultipart(method, url string, i interface{}) *http.Request {
 	buf := bytes.Buffer{}
 	writer := multipart.NewWriter(&buf)
-	defer writer.Close()
 	log.Debug("processMultipart|i", i)
 	switch v := i.(type) {
 	case util.Map:
+		//fields写在文件之前,部分存储服务(如COS)要求文件为最后一个字段
+		fields := v.GetMap("fields")
+		for k := range fields {
+			if err := writer.WriteField(k, fields.GetString(k)); err != nil {
+				log.Error("processMultipart|err", err)
+				return nil
+			}
+		}
 		path := v.GetString("media")
 		fh, err := os.Open(path)
 		if err != nil {
@@ -216,7 +223,7 @@ func processMultipart(method, url string, i interface{}) *http.Request {
 			}
 		}()
 
-		fw, err := writer.CreateFormFile("media", path)
+		fw, err := writer.CreateFormFile(v.GetStringD("name", "media"), path)
 		if err != nil {
 			log.Error("processMultipart|err", err)
 			return nil
@@ -231,6 +238,11 @@ func processMultipart(method, url string, i interface{}) *http.Request {
 			_ = writer.WriteField("description", string(des.ToJSON()))
 		}
 	}
+	//需在创建请求前写入结束边界,否则ContentLength与实际长度不一致
+	if err := writer.Close(); err != nil {
+		log.Error("processMultipart|err", err)
+		return nil
+	}
 	request, err := http.NewRequest(method, url, &buf)
 	if err != nil {
 		return nil
@@ -1,7 +1,14 @@
 package core_test
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/util"
 )
 
 // TestNewReqeust ...
@@ -14,3 +21,38 @@ func TestRequest_SafeRequest(t *testing.T) {
 	//r := wego.NewRequest(wego.GetConfig("payment.default"))
 	//r.SafeRequest("hello")
 }
+
+// TestUpload_Fields ...
+func TestUpload_Fields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.txt")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("key") != "dir/upload.txt" || r.FormValue("Signature") != "sign" {
+			t.Error(r.Form)
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if data, _ := ioutil.ReadAll(f); string(data) != "hello" {
+			t.Error(string(data))
+		}
+		_, _ = w.Write([]byte(`{"errcode":0}`))
+	}))
+	defer srv.Close()
+
+	resp := core.Upload(srv.URL, util.Map{}, util.Map{
+		"name":  "file",
+		"media": path,
+		"fields": util.Map{
+			"key":       "dir/upload.txt",
+			"Signature": "sign",
+		},
+	})
+	if err := resp.Error(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/godcong/wego/core/message"
+)
+
+/*Middleware 消息处理中间件 */
+type Middleware func(next MessageCallback) MessageCallback
+
+/*Route 消息路由规则,未设置的条件不参与匹配 */
+type Route struct {
+	msgType    message.MsgType
+	event      message.EventType
+	eventKey   string
+	content    *regexp.Regexp
+	fromUser   string
+	priority   int
+	callback   MessageCallback
+	middleware []Middleware
+}
+
+/*NewRoute 创建路由规则 */
+func NewRoute(callback MessageCallback) *Route {
+	return &Route{
+		callback: callback,
+	}
+}
+
+// MsgType 匹配消息类型
+func (r *Route) MsgType(msgType message.MsgType) *Route {
+	r.msgType = msgType
+	return r
+}
+
+// Event 匹配事件类型(不区分大小写),同时限定消息类型为event
+func (r *Route) Event(event message.EventType) *Route {
+	r.msgType = message.MsgTypeEvent
+	r.event = event
+	return r
+}
+
+// EventKey 匹配EventKey前缀,如 qrscene_
+func (r *Route) EventKey(prefix string) *Route {
+	r.eventKey = prefix
+	return r
+}
+
+// Content 以正则匹配文本内容
+func (r *Route) Content(pattern string) *Route {
+	return r.ContentRegexp(regexp.MustCompile(pattern))
+}
+
+// ContentRegexp 以已编译的正则匹配文本内容
+func (r *Route) ContentRegexp(re *regexp.Regexp) *Route {
+	r.content = re
+	return r
+}
+
+// FromUser 匹配发送者openid
+func (r *Route) FromUser(openID string) *Route {
+	r.fromUser = openID
+	return r
+}
+
+// Priority 优先级,数值大的优先匹配,相同优先级按添加顺序
+func (r *Route) Priority(priority int) *Route {
+	r.priority = priority
+	return r
+}
+
+// Use 添加仅作用于该路由的中间件
+func (r *Route) Use(middleware ...Middleware) *Route {
+	r.middleware = append(r.middleware, middleware...)
+	return r
+}
+
+// Match 判断消息是否满足路由规则
+func (r *Route) Match(msg *Message) bool {
+	if r.msgType != "" && r.msgType != message.MsgTypeAll && msg.GetType().Compare(r.msgType) != 0 {
+		return false
+	}
+	if r.event != "" && !strings.EqualFold(string(msg.Event.Event.Value), string(r.event)) {
+		return false
+	}
+	if r.eventKey != "" && !strings.HasPrefix(msg.EventKey.Value, r.eventKey) {
+		return false
+	}
+	if r.content != nil && !r.content.MatchString(msg.Content.Value) {
+		return false
+	}
+	if r.fromUser != "" && msg.FromUserName.Value != r.fromUser {
+		return false
+	}
+	return true
+}
+
+/*Router 消息路由,Dispatch可直接作为MessageCallback注册到各Server */
+type Router struct {
+	mutex      sync.RWMutex
+	routes     []*Route
+	middleware []Middleware
+	fallback   MessageCallback
+}
+
+/*NewRouter 创建消息路由 */
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Add 添加路由规则
+func (r *Router) Add(routes ...*Route) *Router {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.routes = append(r.routes, routes...)
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return r.routes[i].priority > r.routes[j].priority
+	})
+	return r
+}
+
+// Use 添加作用于所有路由(包括fallback)的中间件
+func (r *Router) Use(middleware ...Middleware) *Router {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+	return r
+}
+
+// Fallback 没有路由匹配时的处理函数
+func (r *Router) Fallback(callback MessageCallback) *Router {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.fallback = callback
+	return r
+}
+
+// Match 返回第一个匹配的路由规则
+func (r *Router) Match(msg *Message) *Route {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, route := range r.routes {
+		if route.Match(msg) {
+			return route
+		}
+	}
+	return nil
+}
+
+// Dispatch 按优先级匹配路由并执行
+func (r *Router) Dispatch(msg *Message) message.Messager {
+	route := r.Match(msg)
+	r.mutex.RLock()
+	callback, middleware := r.fallback, r.middleware
+	r.mutex.RUnlock()
+	if route != nil {
+		callback = chain(route.callback, route.middleware)
+	}
+	if callback == nil {
+		return nil
+	}
+	return chain(callback, middleware)(msg)
+}
+
+func chain(callback MessageCallback, middleware []Middleware) MessageCallback {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		callback = middleware[i](callback)
+	}
+	return callback
+}
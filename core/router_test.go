@@ -0,0 +1,60 @@
+package core_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/godcong/wego/core"
+	"github.com/godcong/wego/core/message"
+)
+
+var textRequest = `<xml><ToUserName><![CDATA[gh_56870ffd193b]]></ToUserName><FromUserName><![CDATA[oLyBi0hSYhggnD-kOIms0IzZFqrc]]></FromUserName><CreateTime>1524409354</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hello]]></Content><MsgId>6547288321577417974</MsgId></xml>`
+
+// TestRouter_Dispatch ...
+func TestRouter_Dispatch(t *testing.T) {
+	reply := func(content string) core.MessageCallback {
+		return func(msg *core.Message) message.Messager {
+			return message.NewText(&msg.Message, content)
+		}
+	}
+	var logged []string
+	router := core.NewRouter().Add(
+		core.NewRoute(reply("keyword")).MsgType(message.MsgTypeText).Content("^hel+o$"),
+		core.NewRoute(reply("vip")).MsgType(message.MsgTypeText).FromUser("oLyBi0hSYhggnD-kOIms0IzZFqrc").Priority(10),
+		core.NewRoute(reply("scene")).Event(message.EventTypeSubscribe).EventKey("qrscene_"),
+	).Fallback(reply("fallback")).Use(func(next core.MessageCallback) core.MessageCallback {
+		return func(msg *core.Message) message.Messager {
+			logged = append(logged, msg.FromUserName.Value)
+			return next(msg)
+		}
+	})
+
+	dispatch := func(body string) string {
+		msg := new(core.Message)
+		if err := xml.Unmarshal([]byte(body), msg); err != nil {
+			t.Fatal(err)
+		}
+		if rlt, b := router.Dispatch(msg).(*message.Text); b {
+			return rlt.Content.Value
+		}
+		return ""
+	}
+
+	if rlt := dispatch(textRequest); rlt != "vip" {
+		t.Error(rlt)
+	}
+	if rlt := dispatch(strings.Replace(textRequest, "oLyBi0hSYhggnD-kOIms0IzZFqrc", "other", 1)); rlt != "keyword" {
+		t.Error(rlt)
+	}
+	subscribe := `<xml><ToUserName><![CDATA[gh_56870ffd193b]]></ToUserName><FromUserName><![CDATA[other]]></FromUserName><CreateTime>1524409354</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[subscribe]]></Event><EventKey><![CDATA[qrscene_123]]></EventKey></xml>`
+	if rlt := dispatch(subscribe); rlt != "scene" {
+		t.Error(rlt)
+	}
+	if rlt := dispatch(strings.Replace(subscribe, "qrscene_123", "", 1)); rlt != "fallback" {
+		t.Error(rlt)
+	}
+	if len(logged) != 4 {
+		t.Error(logged)
+	}
+}
@@ -1,6 +1,7 @@
 package core_test
 
 import (
+	"github.com/godcong/wego"
 	"github.com/godcong/wego/core"
 	"github.com/godcong/wego/util"
 	"testing"
@@ -8,7 +9,7 @@ import (
 
 // TestNewURL ...
 func TestNewURL(t *testing.T) {
-	url := core.NewURL(core.C(util.Map{
+	url := core.NewURL(wego.C(util.Map{
 		"app_id": "wx3c69535993f4651d",
 		"secret": "f8c7a2cf0c6ed44e2c719964bbe13b1e",
 	}))
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/godcong/wego/cache"
+)
+
+// DefaultTimestampSkew 默认允许的回调时间戳偏差
+const DefaultTimestampSkew = 5 * time.Minute
+
+// ErrInvalidSignature 签名校验失败
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ErrInvalidTimestamp 时间戳格式错误
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// ErrTimestampExpired 时间戳超出允许偏差
+var ErrTimestampExpired = errors.New("timestamp expired")
+
+// ErrNonceReplayed nonce被重复使用
+var ErrNonceReplayed = errors.New("nonce replayed")
+
+/*Verifier 回调请求时间戳及nonce防重放校验
+微信重试推送时会复用原请求的timestamp和nonce,默认只校验时间戳,重复推送交给Deduplicator处理
+*/
+type Verifier struct {
+	Skew   time.Duration //允许的时间戳偏差,小于等于0时不校验时间戳
+	Nonce  bool          //是否校验nonce重放,开启后微信的重试推送会被拒绝
+	prefix string
+}
+
+/*NewVerifier 以prefix区分不同应用的nonce缓存 */
+func NewVerifier(prefix string) *Verifier {
+	return &Verifier{
+		Skew:   DefaultTimestampSkew,
+		prefix: prefix,
+	}
+}
+
+// Verify 校验时间戳偏差,开启Nonce时记录nonce,同一nonce在有效期内只允许使用一次
+func (v *Verifier) Verify(timeStamp, nonce string) error {
+	if err := v.CheckTimestamp(timeStamp); err != nil {
+		return err
+	}
+	if !v.Nonce {
+		return nil
+	}
+	return v.CheckNonce(timeStamp, nonce)
+}
+
+// CheckTimestamp 校验时间戳偏差
+func (v *Verifier) CheckTimestamp(timeStamp string) error {
+	if v.Skew <= 0 {
+		return nil
+	}
+	ts, err := strconv.ParseInt(timeStamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	diff := time.Since(time.Unix(ts, 0))
+	if diff > v.Skew || diff < -v.Skew {
+		return ErrTimestampExpired
+	}
+	return nil
+}
+
+// CheckNonce 校验nonce是否重放
+func (v *Verifier) CheckNonce(timeStamp, nonce string) error {
+	if nonce == "" {
+		return nil
+	}
+	key := v.getCacheKey(timeStamp, nonce)
+	if cache.Has(key) {
+		return ErrNonceReplayed
+	}
+	ttl := DefaultTimestampSkew
+	if v.Skew > 0 {
+		ttl = v.Skew
+	}
+	t := time.Now().Add(2 * ttl)
+	cache.SetWithTTL(key, timeStamp, &t)
+	return nil
+}
+
+func (v *Verifier) getCacheKey(timeStamp, nonce string) string {
+	return "godcong.wego.core.verifier.nonce." + v.prefix + "." + fmt.Sprintf("%x", md5.Sum([]byte(timeStamp+nonce)))
+}
@@ -27,6 +27,25 @@ type CDATA struct {
 	Value   string `xml:",cdata"`
 }
 
+/*UnmarshalJSON json格式推送中CDATA为普通字符串 */
+func (c *CDATA) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONString(b, &c.Value)
+}
+
+/*MarshalJSON json格式回复中CDATA输出为普通字符串 */
+func (c CDATA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Value)
+}
+
+func unmarshalJSONString(b []byte, v *string) error {
+	if err := json.Unmarshal(b, v); err == nil {
+		return nil
+	}
+	//数字等非字符串类型直接使用原始值
+	*v = string(b)
+	return nil
+}
+
 /* error types */
 var (
 	ErrorSignType  = errors.New("sign type error")